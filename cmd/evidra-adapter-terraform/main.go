@@ -14,6 +14,7 @@ import (
 func main() {
 	jsonErrors := false
 	formatMode := "input"
+	failOnErrored := false
 	args := os.Args[1:]
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -22,6 +23,8 @@ func main() {
 			os.Exit(0)
 		case "--json-errors":
 			jsonErrors = true
+		case "--fail-on-errored":
+			failOnErrored = true
 		case "--format":
 			if i+1 < len(args) {
 				i++
@@ -30,7 +33,7 @@ func main() {
 				exitError(jsonErrors, "USAGE_ERROR", "--format requires a value (input or full)", "", 2)
 			}
 		case "--help", "-h":
-			fmt.Fprintf(os.Stderr, "Usage: terraform show -json tfplan.bin | evidra-adapter-terraform [--format input|full] [--json-errors]\n")
+			fmt.Fprintf(os.Stderr, "Usage: terraform show -json tfplan.bin | evidra-adapter-terraform [--format input|full] [--json-errors] [--fail-on-errored]\n")
 			os.Exit(0)
 		default:
 			exitError(jsonErrors, "USAGE_ERROR", fmt.Sprintf("unknown flag: %s", args[i]), "", 2)
@@ -57,18 +60,39 @@ func main() {
 		"filter_actions",
 		"include_data_sources",
 		"max_resource_changes",
+		"protected_resources",
+		"redact_attributes",
+		"redaction_salt",
+		"required_tags",
 		"resource_changes_sort",
 		"truncate_strategy",
+		"trusted_account_ids",
 	}
 	for _, key := range envKeys {
 		if v := os.Getenv("EVIDRA_" + strings.ToUpper(key)); v != "" {
 			config[key] = v
 		}
 	}
+	// File-valued settings are read here so that Convert stays pure.
+	for _, key := range fileKeys {
+		if path := os.Getenv(fileEnv(key)); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				exitError(jsonErrors, "CONFIG_ERROR", fmt.Sprintf("read %s: %v", key, err), "", 2)
+			}
+			config[key] = string(data)
+		}
+	}
 
 	a := &terraform.PlanAdapter{}
 	result, err := a.Convert(context.Background(), raw, config)
 	if err != nil {
+		for _, key := range fileKeys {
+			if strings.Contains(err.Error(), key+":") {
+				exitError(jsonErrors, "CONFIG_ERROR", err.Error(),
+					"Check the file named by "+fileEnv(key), 2)
+			}
+		}
 		code := "PARSE_ERROR"
 		if strings.Contains(err.Error(), "validate") {
 			code = "VALIDATION_ERROR"
@@ -77,6 +101,12 @@ func main() {
 			"Ensure input is from `terraform show -json`, not `terraform plan`", 1)
 	}
 
+	if failOnErrored && result.Input["plan_errored"] == true {
+		exitError(jsonErrors, "PLAN_ERRORED",
+			"plan errored: Terraform stopped planning partway, the plan is incomplete",
+			"Fix the errors reported by `terraform plan` and re-run it", 3)
+	}
+
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	var output any
@@ -90,6 +120,14 @@ func main() {
 	}
 }
 
+// fileKeys are config keys whose value is the content of the file named
+// by the matching EVIDRA_*_FILE variable.
+var fileKeys = []string{"price_table", "risk_weights"}
+
+func fileEnv(key string) string {
+	return "EVIDRA_" + strings.ToUpper(key) + "_FILE"
+}
+
 type errorEnvelope struct {
 	Error errorDetail `json:"error"`
 }
@@ -296,6 +296,140 @@ func TestCLI_ValidationError_JsonErrors(t *testing.T) {
 	}
 }
 
+func TestCLI_FailOnErrored(t *testing.T) {
+	binary := buildTestBinary(t)
+	fixture := loadFixture(t, "errored_plan.json")
+
+	// Without the flag an errored plan converts and is flagged.
+	cmd := exec.Command(binary)
+	cmd.Stdin = bytes.NewReader(fixture)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	var input map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &input); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	if input["plan_errored"] != true {
+		t.Errorf("expected plan_errored=true, got %v", input["plan_errored"])
+	}
+
+	// With the flag it fails closed.
+	cmd = exec.Command(binary, "--fail-on-errored", "--json-errors")
+	cmd.Stdin = bytes.NewReader(fixture)
+	var stderr bytes.Buffer
+	stdout.Reset()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected empty stdout, got: %s", stdout.String())
+	}
+	var env struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(stderr.Bytes(), &env); err != nil {
+		t.Fatalf("unmarshal error envelope: %v\nstderr: %s", err, stderr.String())
+	}
+	if env.Error.Code != "PLAN_ERRORED" {
+		t.Errorf("expected code PLAN_ERRORED, got %q", env.Error.Code)
+	}
+
+	// A complete plan passes with the flag.
+	cmd = exec.Command(binary, "--fail-on-errored")
+	cmd.Stdin = bytes.NewReader(loadFixture(t, "simple_create.json"))
+	if err := cmd.Run(); err != nil {
+		t.Errorf("complete plan failed with --fail-on-errored: %v", err)
+	}
+}
+
+func TestCLI_RiskWeightsFile(t *testing.T) {
+	binary := buildTestBinary(t)
+	fixture := loadFixture(t, "simple_create.json")
+
+	weights := filepath.Join(t.TempDir(), "weights.json")
+	if err := os.WriteFile(weights, []byte(`{"resource_types": {"hcloud_server": 10}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(binary)
+	cmd.Env = append(os.Environ(), "EVIDRA_RISK_WEIGHTS_FILE="+weights)
+	cmd.Stdin = bytes.NewReader(fixture)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	var input map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &input); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	// hcloud_server create (1×10) + hcloud_firewall create (1×1).
+	if input["risk_score"] != 11.0 {
+		t.Errorf("expected risk_score=11, got %v", input["risk_score"])
+	}
+
+	// A bad weights file is a configuration error, not a plan error.
+	if err := os.WriteFile(weights, []byte(`{"actions": {"destroy": 1}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd = exec.Command(binary, "--json-errors")
+	cmd.Env = append(os.Environ(), "EVIDRA_RISK_WEIGHTS_FILE="+weights)
+	cmd.Stdin = bytes.NewReader(fixture)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 2 {
+		t.Fatalf("expected exit code 2, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), `"CONFIG_ERROR"`) {
+		t.Errorf("expected CONFIG_ERROR, got: %s", stderr.String())
+	}
+}
+
+func TestCLI_PriceTableFile(t *testing.T) {
+	binary := buildTestBinary(t)
+
+	table := filepath.Join(t.TempDir(), "prices.csv")
+	if err := os.WriteFile(table, []byte("resource_type,monthly\nhcloud_server,5\nhcloud_firewall,0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(binary)
+	cmd.Env = append(os.Environ(), "EVIDRA_PRICE_TABLE_FILE="+table)
+	cmd.Stdin = bytes.NewReader(loadFixture(t, "simple_create.json"))
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	var input map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &input); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	if input["monthly_cost_delta"] != 5.0 {
+		t.Errorf("expected monthly_cost_delta=5, got %v", input["monthly_cost_delta"])
+	}
+
+	// A missing file is a configuration error.
+	cmd = exec.Command(binary)
+	cmd.Env = append(os.Environ(), "EVIDRA_PRICE_TABLE_FILE="+table+".missing")
+	cmd.Stdin = bytes.NewReader(loadFixture(t, "simple_create.json"))
+	err := cmd.Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 2 {
+		t.Fatalf("expected exit code 2, got %v", err)
+	}
+}
+
 func TestCLI_Help(t *testing.T) {
 	binary := buildTestBinary(t)
 
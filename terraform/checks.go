@@ -0,0 +1,51 @@
+package terraform
+
+import (
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// extractCheckResults summarises the plan's checks: check blocks,
+// resource pre/postconditions, output preconditions and variable
+// validations. Each checkable object counts once per instance; an object
+// whose instances are not yet known counts once with its own status.
+// Addresses are capped at max; the counts are never truncated.
+func extractCheckResults(checks []tfjson.CheckResultStatic, max int) map[string]any {
+	counts := map[tfjson.CheckStatus]int{}
+	failed, errored := []string{}, []string{}
+
+	record := func(status tfjson.CheckStatus, address string) {
+		counts[status]++
+		switch status {
+		case tfjson.CheckStatusFail:
+			failed = append(failed, address)
+		case tfjson.CheckStatusError:
+			errored = append(errored, address)
+		}
+	}
+	for _, c := range checks {
+		if len(c.Instances) == 0 {
+			record(c.Status, c.Address.ToDisplay)
+			continue
+		}
+		for _, inst := range c.Instances {
+			record(inst.Status, inst.Address.ToDisplay)
+		}
+	}
+
+	sort.Strings(failed)
+	sort.Strings(errored)
+	failed, _, failedTruncated := truncate(failed, max)
+	errored, _, erroredTruncated := truncate(errored, max)
+
+	return map[string]any{
+		"pass_count":          counts[tfjson.CheckStatusPass],
+		"fail_count":          counts[tfjson.CheckStatusFail],
+		"error_count":         counts[tfjson.CheckStatusError],
+		"unknown_count":       counts[tfjson.CheckStatusUnknown],
+		"failed_addresses":    failed,
+		"errored_addresses":   errored,
+		"addresses_truncated": failedTruncated || erroredTruncated,
+	}
+}
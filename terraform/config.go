@@ -0,0 +1,132 @@
+package terraform
+
+import (
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// configIndex maps resource changes back to their configuration blocks.
+// Configuration is static: it has no instance keys, so both the index
+// keys and lookups strip count/for_each indexes from addresses.
+type configIndex struct {
+	// resources is keyed by static address, e.g.
+	// "module.network.aws_subnet.private" or "data.aws_ami.ubuntu".
+	resources map[string]*tfjson.ConfigResource
+
+	// modules is keyed by static module path, "" for the root module.
+	modules map[string]*tfjson.ConfigModule
+}
+
+func newConfigIndex(cfg *tfjson.Config) *configIndex {
+	idx := &configIndex{
+		resources: map[string]*tfjson.ConfigResource{},
+		modules:   map[string]*tfjson.ConfigModule{},
+	}
+	if cfg != nil && cfg.RootModule != nil {
+		idx.add("", cfg.RootModule)
+	}
+	return idx
+}
+
+func (idx *configIndex) add(path string, m *tfjson.ConfigModule) {
+	idx.modules[path] = m
+	for _, r := range m.Resources {
+		idx.resources[joinAddress(path, localAddress(r.Mode, r.Type, r.Name))] = r
+	}
+	for name, call := range m.ModuleCalls {
+		if call.Module != nil {
+			idx.add(joinAddress(path, "module."+name), call.Module)
+		}
+	}
+}
+
+// lookup returns the configuration block of a resource change, or nil
+// when the resource is no longer in configuration (e.g. it is being
+// destroyed because its block was removed).
+func (idx *configIndex) lookup(rc *tfjson.ResourceChange) *tfjson.ConfigResource {
+	return idx.resources[staticAddress(rc)]
+}
+
+// moduleCall returns the call that instantiates the module at path and
+// the path of the calling module, or nil for the root module.
+func (idx *configIndex) moduleCall(path string) (*tfjson.ModuleCall, string) {
+	i := strings.LastIndex(path, "module.")
+	if i < 0 {
+		return nil, ""
+	}
+	parent := strings.TrimSuffix(path[:i], ".")
+	m := idx.modules[parent]
+	if m == nil {
+		return nil, ""
+	}
+	return m.ModuleCalls[path[i+len("module."):]], parent
+}
+
+// staticAddress is the configuration address of a resource change:
+// its module path and local name without instance keys.
+func staticAddress(rc *tfjson.ResourceChange) string {
+	return joinAddress(stripIndexes(rc.ModuleAddress), localAddress(rc.Mode, rc.Type, rc.Name))
+}
+
+// resolveReference maps an expression reference made inside module path
+// to the static address of the resource it points at. References to
+// anything other than a resource (var., local., module. etc.) return "".
+func resolveReference(path, ref string) string {
+	parts := strings.Split(stripIndexes(ref), ".")
+	switch parts[0] {
+	case "var", "local", "module", "each", "count", "path", "terraform", "self":
+		return ""
+	case "data":
+		if len(parts) < 3 {
+			return ""
+		}
+		return joinAddress(path, strings.Join(parts[:3], "."))
+	}
+	if len(parts) < 2 {
+		return ""
+	}
+	return joinAddress(path, strings.Join(parts[:2], "."))
+}
+
+func localAddress(mode tfjson.ResourceMode, typ, name string) string {
+	if mode == tfjson.DataResourceMode {
+		return "data." + typ + "." + name
+	}
+	return typ + "." + name
+}
+
+func joinAddress(path, local string) string {
+	if path == "" {
+		return local
+	}
+	return path + "." + local
+}
+
+// stripIndexes removes instance keys ([0], ["a.b"]) from an address.
+// Quoted keys may contain brackets and dots, so quotes are tracked.
+func stripIndexes(addr string) string {
+	var b strings.Builder
+	depth := 0
+	quoted := false
+	for i := 0; i < len(addr); i++ {
+		c := addr[i]
+		switch {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"' && depth > 0:
+			quoted = true
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
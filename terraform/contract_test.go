@@ -23,7 +23,8 @@ func TestAdapterOutputSatisfiesKillSwitch(t *testing.T) {
 	// resource_types, s3_public_access_block, server_side_encryption,
 	// security_group_rules, iam_policy_statements, trust_policy_statements
 	//
-	// v1 adapter provides resource_types. The rest are planned for v2.
+	// The adapter always provides resource_types; the deep extraction
+	// fields are checked below.
 
 	// resource_types: must exist, be array, len > 0
 	types, ok := result.Input["resource_types"]
@@ -100,6 +101,24 @@ func TestAdapterOutputSatisfiesKillSwitch(t *testing.T) {
 		}
 	}
 
+	// Deep extraction arrays must be present even when empty.
+	for _, field := range []string{
+		"security_group_rules",
+		"iam_policy_statements",
+		"trust_policy_statements",
+		"s3_public_access_block",
+		"server_side_encryption",
+	} {
+		v, ok := result.Input[field]
+		if !ok {
+			t.Errorf("%s missing", field)
+			continue
+		}
+		if _, isSlice := v.([]map[string]any); !isSlice {
+			t.Errorf("%s is %T, want []map[string]any", field, v)
+		}
+	}
+
 	// metadata.warnings must be present (reserved for v2).
 	warnings, ok := result.Metadata["warnings"]
 	if !ok {
@@ -0,0 +1,228 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// sizeAttributes select the price table row of a resource, first
+// present wins: EC2 instance_type, Hetzner server_type, RDS
+// instance_class.
+var sizeAttributes = []string{"instance_type", "server_type", "instance_class"}
+
+// storageAttributes hold the storage size in GB that per_gb_monthly
+// applies to: RDS allocated_storage, EBS and Hetzner volume size.
+var storageAttributes = []string{"allocated_storage", "size"}
+
+// priceEntry is one row of the price table. size is empty for a row
+// that applies to every size of the type (or to types without one).
+type priceEntry struct {
+	ResourceType string  `json:"resource_type"`
+	Size         string  `json:"size"`
+	Monthly      float64 `json:"monthly"`
+	PerGBMonthly float64 `json:"per_gb_monthly"`
+}
+
+// priceTable maps resource type and size to a price.
+type priceTable map[string]map[string]priceEntry
+
+// parsePriceTable reads the price_table config key: a JSON array of
+// entries, or CSV with a header row naming the same columns. Errors
+// are reported rather than ignored: a half-read table would price a
+// plan too low.
+func parsePriceTable(doc string) (priceTable, error) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return priceTable{}, nil
+	}
+	var entries []priceEntry
+	var err error
+	if strings.HasPrefix(doc, "[") {
+		dec := json.NewDecoder(strings.NewReader(doc))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&entries)
+	} else {
+		entries, err = parsePriceCSV(doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	table := priceTable{}
+	for i, e := range entries {
+		if e.ResourceType == "" {
+			return nil, fmt.Errorf("entry %d: resource_type is required", i+1)
+		}
+		if e.Monthly < 0 || e.PerGBMonthly < 0 {
+			return nil, fmt.Errorf("entry %d: negative price", i+1)
+		}
+		if table[e.ResourceType] == nil {
+			table[e.ResourceType] = map[string]priceEntry{}
+		}
+		if _, dup := table[e.ResourceType][e.Size]; dup {
+			return nil, fmt.Errorf("entry %d: duplicate %s %q", i+1, e.ResourceType, e.Size)
+		}
+		table[e.ResourceType][e.Size] = e
+	}
+	return table, nil
+}
+
+func parsePriceCSV(doc string) ([]priceEntry, error) {
+	r := csv.NewReader(bytes.NewReader([]byte(doc)))
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		switch name {
+		case "resource_type", "size", "monthly", "per_gb_monthly":
+			cols[name] = i
+		default:
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	if _, ok := cols["resource_type"]; !ok {
+		return nil, fmt.Errorf("resource_type column is required")
+	}
+	cell := func(rec []string, name string) string {
+		if i, ok := cols[name]; ok {
+			return rec[i]
+		}
+		return ""
+	}
+	price := func(rec []string, name string) (float64, error) {
+		s := cell(rec, name)
+		if s == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(s, 64)
+	}
+	var entries []priceEntry
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		monthly, err := price(rec, "monthly")
+		if err != nil {
+			return nil, fmt.Errorf("line %d: monthly: %w", line, err)
+		}
+		perGB, err := price(rec, "per_gb_monthly")
+		if err != nil {
+			return nil, fmt.Errorf("line %d: per_gb_monthly: %w", line, err)
+		}
+		entries = append(entries, priceEntry{
+			ResourceType: cell(rec, "resource_type"),
+			Size:         cell(rec, "size"),
+			Monthly:      monthly,
+			PerGBMonthly: perGB,
+		})
+	}
+}
+
+// price returns the monthly price of one side of a change. ok is false
+// when the table has no row for the resource or the attributes it needs
+// are unknown until apply.
+func (t priceTable) price(typ string, values, unknown map[string]any) (float64, bool) {
+	rows := t[typ]
+	if rows == nil {
+		return 0, false
+	}
+	size := ""
+	for _, attr := range sizeAttributes {
+		if unknown[attr] == true {
+			return 0, false
+		}
+		if size = attrString(values, attr); size != "" {
+			break
+		}
+	}
+	e, ok := rows[size]
+	if !ok {
+		if e, ok = rows[""]; !ok {
+			return 0, false
+		}
+	}
+	cost := e.Monthly
+	if e.PerGBMonthly > 0 {
+		for _, attr := range storageAttributes {
+			if unknown[attr] == true {
+				return 0, false
+			}
+			if gb, ok := attrInt(values, attr); ok {
+				cost += e.PerGBMonthly * float64(gb)
+				break
+			}
+		}
+	}
+	return cost, true
+}
+
+// extractCostDelta estimates the change in monthly cost of the managed
+// resources that are created, updated, replaced or deleted. Each priced
+// change becomes {address, type, action, before_monthly, after_monthly,
+// monthly_delta}; the others are listed as unpriced. Amounts are in the
+// currency of the table, rounded to cents.
+func extractCostDelta(changes []*tfjson.ResourceChange, table priceTable) (float64, []map[string]any, []string) {
+	total := 0.0
+	breakdown := []map[string]any{}
+	unpriced := []string{}
+	for _, rc := range changes {
+		if rc.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+		action := primaryAction(rc.Change.Actions)
+		var before, after float64
+		ok := true
+		switch action {
+		case "create":
+			after, ok = table.price(rc.Type, asMap(rc.Change.After), asMap(rc.Change.AfterUnknown))
+		case "delete":
+			before, ok = table.price(rc.Type, asMap(rc.Change.Before), nil)
+		case "update", "replace":
+			var okBefore, okAfter bool
+			before, okBefore = table.price(rc.Type, asMap(rc.Change.Before), nil)
+			after, okAfter = table.price(rc.Type, asMap(rc.Change.After), asMap(rc.Change.AfterUnknown))
+			ok = okBefore && okAfter
+		default:
+			continue
+		}
+		if !ok {
+			unpriced = append(unpriced, rc.Address)
+			continue
+		}
+		total += after - before
+		breakdown = append(breakdown, map[string]any{
+			"address":        rc.Address,
+			"type":           rc.Type,
+			"action":         action,
+			"before_monthly": roundCents(before),
+			"after_monthly":  roundCents(after),
+			"monthly_delta":  roundCents(after - before),
+		})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		return breakdown[i]["address"].(string) < breakdown[j]["address"].(string)
+	})
+	sort.Strings(unpriced)
+	return roundCents(total), breakdown, unpriced
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
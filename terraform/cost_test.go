@@ -0,0 +1,131 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+const priceTableCSV = `# Hetzner and AWS list prices, EUR per month
+resource_type,size,monthly,per_gb_monthly
+hcloud_server,cx22,4.59,
+hcloud_server,cx32,7.59,
+hcloud_volume,,,0.052
+hcloud_firewall,,0,
+aws_db_instance,db.t3.micro,12.41,0.115
+`
+
+const priceTableJSON = `[
+	{"resource_type": "hcloud_server", "size": "cx22", "monthly": 4.59},
+	{"resource_type": "hcloud_server", "size": "cx32", "monthly": 7.59},
+	{"resource_type": "hcloud_volume", "per_gb_monthly": 0.052},
+	{"resource_type": "hcloud_firewall", "monthly": 0},
+	{"resource_type": "aws_db_instance", "size": "db.t3.micro", "monthly": 12.41, "per_gb_monthly": 0.115}
+]`
+
+func TestPlanAdapter_CostDelta(t *testing.T) {
+	t.Parallel()
+
+	for name, table := range map[string]string{"csv": priceTableCSV, "json": priceTableJSON} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			raw := loadFixture(t, "cost.json")
+			result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+				"price_table": table,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// web +4.59, api cx22→cx32 +3.00, volume 50 GB +2.60,
+			// firewall 0, reports 12.41 + 100 GB +23.91.
+			if got := result.Input["monthly_cost_delta"]; got != 34.10 {
+				t.Errorf("monthly_cost_delta: expected 34.10, got %v", got)
+			}
+
+			breakdown := map[string]map[string]any{}
+			for _, e := range result.Input["cost_breakdown"].([]map[string]any) {
+				breakdown[e["address"].(string)] = e
+			}
+			if len(breakdown) != 5 {
+				t.Fatalf("cost_breakdown: expected 5 entries, got %d", len(breakdown))
+			}
+			api := breakdown["hcloud_server.api"]
+			if api["before_monthly"] != 4.59 || api["after_monthly"] != 7.59 || api["monthly_delta"] != 3.0 {
+				t.Errorf("hcloud_server.api: unexpected prices %v", api)
+			}
+			if got := breakdown["aws_db_instance.reports"]["after_monthly"]; got != 23.91 {
+				t.Errorf("aws_db_instance.reports.after_monthly: expected 23.91, got %v", got)
+			}
+
+			// main: storage unknown until apply; old: no cx11 row.
+			want := []string{"aws_db_instance.main", "hcloud_server.old"}
+			if got := result.Input["unpriced_resources"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("unpriced_resources: expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestPlanAdapter_CostDelta_NoTable(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "cost.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.Input["monthly_cost_delta"]; got != 0.0 {
+		t.Errorf("monthly_cost_delta: expected 0, got %v", got)
+	}
+	// Every managed change is unpriced; no-ops and data sources are not listed.
+	assertInt(t, "unpriced_resources_total", 7, result.Input["unpriced_resources_total"])
+}
+
+func TestPlanAdapter_CostDelta_ScopeAndTruncation(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "cost.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"price_table":           priceTableCSV,
+		"filter_resource_types": "hcloud_server",
+		"max_resource_changes":  "1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.Input["monthly_cost_delta"]; got != 7.59 {
+		t.Errorf("monthly_cost_delta: expected 7.59 (computed before truncation), got %v", got)
+	}
+	assertInt(t, "cost_breakdown_total", 2, result.Input["cost_breakdown_total"])
+	assertBool(t, "cost_breakdown_truncated", true, result.Input["cost_breakdown_truncated"])
+}
+
+func TestPlanAdapter_CostDelta_InvalidTable(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "simple_create.json")
+	for _, tc := range []struct {
+		name, table, wantErr string
+	}{
+		{"unknown column", "resource_type,price\nhcloud_server,1\n", `unknown column "price"`},
+		{"bad number", "resource_type,size,monthly\nhcloud_server,cx22,abc\n", "line 2: monthly"},
+		{"duplicate", "resource_type,size,monthly\nhcloud_server,cx22,1\nhcloud_server,cx22,2\n", "duplicate"},
+		{"missing type", `[{"size": "cx22", "monthly": 1}]`, "resource_type is required"},
+		{"unknown field", `[{"resource_type": "hcloud_server", "price": 1}]`, "unknown field"},
+		{"negative", `[{"resource_type": "hcloud_server", "monthly": -1}]`, "negative price"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+				"price_table": tc.table,
+			})
+			if err == nil || !strings.Contains(err.Error(), "price_table") || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected price_table error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package terraform
+
+import (
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// deferredChange pairs a deferred resource change with its reason.
+type deferredChange struct {
+	rc     *tfjson.ResourceChange
+	reason string
+}
+
+// extractDeferredChanges describes the changes Terraform postponed to a
+// later plan/apply round (-allow-deferral, stacks), sorted by address,
+// and counts them by reason.
+func extractDeferredChanges(deferred []deferredChange) ([]map[string]any, map[string]int) {
+	out := make([]map[string]any, 0, len(deferred))
+	reasons := map[string]int{}
+	for _, d := range deferred {
+		reason := d.reason
+		if reason == "" {
+			reason = "unknown"
+		}
+		reasons[reason]++
+		out = append(out, map[string]any{
+			"address": d.rc.Address,
+			"type":    d.rc.Type,
+			"action":  primaryAction(d.rc.Change.Actions),
+			"reason":  reason,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i]["address"].(string) < out[j]["address"].(string)
+	})
+	return out, reasons
+}
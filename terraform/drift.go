@@ -0,0 +1,61 @@
+package terraform
+
+import (
+	"reflect"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// extractResourceDrift describes resources that changed outside of
+// Terraform since the last apply, as detected by the refresh. Returns
+// the entries sorted by address and the set of drifted types.
+func extractResourceDrift(drift []*tfjson.ResourceChange) ([]map[string]any, []string) {
+	out := make([]map[string]any, 0, len(drift))
+	types := map[string]bool{}
+	for _, rc := range drift {
+		types[rc.Type] = true
+		out = append(out, map[string]any{
+			"address":       rc.Address,
+			"type":          rc.Type,
+			"action":        primaryAction(rc.Change.Actions),
+			"changed_paths": changedPaths(rc.Change.Before, rc.Change.After),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i]["address"].(string) < out[j]["address"].(string)
+	})
+	return out, sortedKeys(types)
+}
+
+// changedPaths returns the sorted paths of the values that differ
+// between before and after, descending into objects. Lists are compared
+// as a whole: element positions of sets and nested blocks are not stable
+// enough to diff meaningfully. Redacted values are leaves. A value that
+// appears or disappears as a whole (create, delete) has no paths.
+func changedPaths(before, after any) []string {
+	paths := []string{}
+	var walk func(path []any, b, a any)
+	walk = func(path []any, b, a any) {
+		bm, am := asMap(b), asMap(a)
+		if bm == nil || am == nil || isRedacted(bm) || isRedacted(am) {
+			if !reflect.DeepEqual(b, a) && len(path) > 0 {
+				paths = append(paths, formatPath(path))
+			}
+			return
+		}
+		keys := map[string]bool{}
+		for k := range bm {
+			keys[k] = true
+		}
+		for k := range am {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			walk(append(path[:len(path):len(path)], k), bm[k], am[k])
+		}
+	}
+	walk(nil, before, after)
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,51 @@
+package terraform
+
+import "encoding/json"
+
+// planExtras holds plan JSON fields that terraform-json does not model
+// yet. It is decoded from the same bytes as tfjson.Plan, so its
+// resource_changes entries line up with plan.ResourceChanges by position.
+type planExtras struct {
+	// Errored is set by Terraform 1.6+ when planning failed partway;
+	// the plan JSON is still written but covers only what was planned.
+	Errored bool `json:"errored"`
+
+	// Applyable (Terraform 1.7+) is false when the plan has nothing to
+	// apply or cannot be applied. nil for older versions.
+	Applyable *bool `json:"applyable"`
+
+	ResourceChanges []struct {
+		ActionReason string `json:"action_reason"`
+	} `json:"resource_changes"`
+
+	// Some Terraform builds nest the deferral reason as
+	// {"deferred": {"reason": ...}} instead of a top-level "reason".
+	DeferredChanges []struct {
+		Deferred struct {
+			Reason string `json:"reason"`
+		} `json:"deferred"`
+	} `json:"deferred_changes"`
+}
+
+// parseExtras decodes the fields above. The input already decoded as a
+// tfjson.Plan, so a failure here only means a field had an unexpected
+// shape; the zero value is a safe fallback.
+func parseExtras(raw []byte) planExtras {
+	var e planExtras
+	_ = json.Unmarshal(raw, &e)
+	return e
+}
+
+func (e planExtras) actionReason(i int) string {
+	if i < len(e.ResourceChanges) {
+		return e.ResourceChanges[i].ActionReason
+	}
+	return ""
+}
+
+func (e planExtras) deferredReason(i int) string {
+	if i < len(e.DeferredChanges) {
+		return e.DeferredChanges[i].Deferred.Reason
+	}
+	return ""
+}
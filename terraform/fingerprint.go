@@ -0,0 +1,112 @@
+package terraform
+
+import (
+	"encoding/json"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// extractVariables lists the root module variables by name with a hash
+// of each value, so two plans of the same code can be compared for
+// "different inputs". Plan JSON carries sensitive variable values in
+// clear text: those, and variables whose name matches a redaction
+// pattern, are only flagged.
+//
+// The hash must be stable across plans, so it is salted with the
+// configured redaction_salt (not the per-plan default). Without one no
+// hash is emitted: typical values like "prod" or "eu-central-1" are
+// easily recovered from an unsalted hash with a small dictionary.
+func extractVariables(plan *tfjson.Plan, redact *redactor, salt string) []map[string]any {
+	var sensitive map[string]*tfjson.ConfigVariable
+	if plan.Config != nil && plan.Config.RootModule != nil {
+		sensitive = plan.Config.RootModule.Variables
+	}
+
+	names := make([]string, 0, len(plan.Variables))
+	for name := range plan.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		entry := map[string]any{"name": name}
+		if cv := sensitive[name]; (cv != nil && cv.Sensitive) || redact.matches(name) {
+			entry["sensitive"] = true
+		} else {
+			var value any
+			if v := plan.Variables[name]; v != nil {
+				value = v.Value
+			}
+			entry["sensitive"] = false
+			if salt != "" {
+				data, _ := json.Marshal(value)
+				entry["value_sha256"] = sha256Hex(append([]byte(salt), data...))
+			}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// extractProviderConfigs lists the provider configurations in the
+// configuration, sorted by their configuration key ("aws",
+// "aws.west", "module.net:aws"). region is set only when the
+// configuration gives it as a constant.
+func extractProviderConfigs(cfg *tfjson.Config) []map[string]any {
+	out := []map[string]any{}
+	if cfg == nil {
+		return out
+	}
+	keys := make([]string, 0, len(cfg.ProviderConfigs))
+	for key := range cfg.ProviderConfigs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		pc := cfg.ProviderConfigs[key]
+		if pc == nil {
+			continue
+		}
+		entry := map[string]any{
+			"name":               pc.Name,
+			"full_name":          pc.FullName,
+			"alias":              pc.Alias,
+			"module_address":     pc.ModuleAddress,
+			"version_constraint": pc.VersionConstraint,
+		}
+		if region, ok := constantString(pc.Expressions["region"]); ok {
+			entry["region"] = region
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// constantString returns the value of an expression that is a string
+// literal. Expressions with references have no constant value.
+func constantString(expr *tfjson.Expression) (string, bool) {
+	if expr == nil || expr.ExpressionData == nil || len(expr.References) > 0 {
+		return "", false
+	}
+	s, ok := expr.ConstantValue.(string)
+	return s, ok
+}
+
+// constantStrings returns the string elements of an expression that is
+// a list literal.
+func constantStrings(expr *tfjson.Expression) []string {
+	if expr == nil || expr.ExpressionData == nil || len(expr.References) > 0 {
+		return nil
+	}
+	list, _ := expr.ConstantValue.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
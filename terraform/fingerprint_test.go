@@ -0,0 +1,120 @@
+package terraform_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func variablesByName(t *testing.T, raw []byte, config map[string]string) map[string]map[string]any {
+	t.Helper()
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := map[string]map[string]any{}
+	for _, v := range result.Input["variables"].([]map[string]any) {
+		out[v["name"].(string)] = v
+	}
+	return out
+}
+
+func TestPlanAdapter_Variables(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "inputs.json")
+	vars := variablesByName(t, raw, nil)
+	if len(vars) != 5 {
+		t.Fatalf("expected 5 variables, got %d", len(vars))
+	}
+
+	// Sensitive in configuration, or by name: flagged, never hashed.
+	for _, name := range []string{"db_password", "api_token"} {
+		assertBool(t, name+".sensitive", true, vars[name]["sensitive"])
+		if _, ok := vars[name]["value_sha256"]; ok {
+			t.Errorf("%s: sensitive variable must not be hashed", name)
+		}
+	}
+
+	// Without a salt, no hashes: an unsalted hash of "eu-central-1" is
+	// reversible with a dictionary.
+	assertBool(t, "region.sensitive", false, vars["region"]["sensitive"])
+	for name, v := range vars {
+		if _, ok := v["value_sha256"]; ok {
+			t.Errorf("%s: value_sha256 must be absent without redaction_salt", name)
+		}
+	}
+
+	salt := map[string]string{"redaction_salt": "org-salt"}
+	vars = variablesByName(t, raw, salt)
+	region := vars["region"]
+	if h, _ := region["value_sha256"].(string); len(h) != 64 {
+		t.Errorf("region.value_sha256: expected 64-char hex, got %q", h)
+	}
+	for _, name := range []string{"db_password", "api_token"} {
+		if _, ok := vars[name]["value_sha256"]; ok {
+			t.Errorf("%s: sensitive variable must not be hashed, even with a salt", name)
+		}
+	}
+
+	// Same inputs hash the same across plans; different inputs do not.
+	again := variablesByName(t, raw, salt)
+	assertStr(t, "region hash stable", region["value_sha256"].(string), again["region"]["value_sha256"])
+	changed := variablesByName(t, bytes.Replace(raw, []byte(`"value": 3`), []byte(`"value": 4`), 1), salt)
+	if changed["instance_count"]["value_sha256"] == vars["instance_count"]["value_sha256"] {
+		t.Error("instance_count: different values must hash differently")
+	}
+	assertStr(t, "tags hash unaffected", vars["tags"]["value_sha256"].(string), changed["tags"]["value_sha256"])
+
+	resalted := variablesByName(t, raw, map[string]string{"redaction_salt": "other-salt"})
+	if resalted["region"]["value_sha256"] == region["value_sha256"] {
+		t.Error("redaction_salt must change the hash")
+	}
+
+	// Values never appear in the output.
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, salt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, _ := json.Marshal(result)
+	for _, value := range []string{"hunter2", "tok-123", "platform"} {
+		if bytes.Contains(out, []byte(value)) {
+			t.Errorf("output contains variable value %q", value)
+		}
+	}
+}
+
+func TestPlanAdapter_ProviderConfigs(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "inputs.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	configs := result.Input["provider_configs"].([]map[string]any)
+	want := []map[string]any{
+		{
+			"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "alias": "",
+			"module_address": "", "version_constraint": "~> 5.0", "region": "eu-west-1",
+		},
+		{
+			// region comes from a variable: not a constant, omitted.
+			"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "alias": "west",
+			"module_address": "", "version_constraint": "~> 5.0",
+		},
+		{
+			"name": "hcloud", "full_name": "registry.terraform.io/hetznercloud/hcloud", "alias": "",
+			"module_address": "module.net", "version_constraint": "",
+		},
+	}
+	if !reflect.DeepEqual(configs, want) {
+		t.Errorf("provider_configs:\n got %v\nwant %v", configs, want)
+	}
+	assertInt(t, "provider_configs_total", 3, result.Input["provider_configs_total"])
+}
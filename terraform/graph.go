@@ -0,0 +1,225 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// depGraph is the resource dependency graph of the configuration, at
+// the static level: nodes are configuration addresses without instance
+// keys. Edges come from expression references, count/for_each, and
+// depends_on on resources and module calls. References through input
+// variables and module outputs are followed across module boundaries.
+// Plan JSON does not include locals, so a dependency that only flows
+// through local.* is not seen.
+type depGraph struct {
+	idx *configIndex
+	// dependents maps a resource to the resources that refer to it.
+	dependents map[string]map[string]bool
+}
+
+func newDepGraph(idx *configIndex) *depGraph {
+	g := &depGraph{idx: idx, dependents: map[string]map[string]bool{}}
+	for path, m := range idx.modules {
+		for _, r := range m.Resources {
+			from := joinAddress(path, localAddress(r.Mode, r.Type, r.Name))
+			var refs []string
+			for _, expr := range r.Expressions {
+				refs = append(refs, expressionRefs(expr)...)
+			}
+			refs = append(refs, expressionRefs(r.CountExpression)...)
+			refs = append(refs, expressionRefs(r.ForEachExpression)...)
+			for _, to := range g.resolveAll(path, append(refs, r.DependsOn...)) {
+				g.addEdge(from, to)
+			}
+			// Module-level count, for_each and depends_on apply to every
+			// resource inside the module, nested modules included.
+			for p := path; p != ""; {
+				call, parent := idx.moduleCall(p)
+				if call == nil {
+					break
+				}
+				refs := append(expressionRefs(call.CountExpression), expressionRefs(call.ForEachExpression)...)
+				for _, to := range g.resolveAll(parent, append(refs, call.DependsOn...)) {
+					g.addEdge(from, to)
+				}
+				p = parent
+			}
+		}
+	}
+	return g
+}
+
+func (g *depGraph) addEdge(from, to string) {
+	if from == to {
+		return
+	}
+	if g.dependents[to] == nil {
+		g.dependents[to] = map[string]bool{}
+	}
+	g.dependents[to][from] = true
+}
+
+// resolveAll maps references made in module path to the resources they
+// lead to. Terraform lists both module.net and module.net.vpc_id for an
+// output reference; the bare form is dropped when a specific one exists.
+func (g *depGraph) resolveAll(path string, refs []string) []string {
+	specific := map[string]bool{}
+	for _, ref := range refs {
+		parts := strings.Split(stripIndexes(ref), ".")
+		if parts[0] == "module" && len(parts) >= 3 {
+			specific["module."+parts[1]] = true
+		}
+	}
+	targets := map[string]bool{}
+	for _, ref := range refs {
+		if specific[stripIndexes(ref)] {
+			continue
+		}
+		for _, t := range g.resolve(path, ref, map[string]bool{}) {
+			targets[t] = true
+		}
+	}
+	return sortedKeys(targets)
+}
+
+// resolve follows one reference to resources. seen guards against
+// reference cycles through variables and outputs.
+func (g *depGraph) resolve(path, ref string, seen map[string]bool) []string {
+	key := path + "|" + ref
+	if seen[key] {
+		return nil
+	}
+	seen[key] = true
+
+	parts := strings.Split(stripIndexes(ref), ".")
+	switch parts[0] {
+	case "var":
+		// A module input: follow the argument of the module call.
+		call, parent := g.idx.moduleCall(path)
+		if call == nil || len(parts) < 2 {
+			return nil
+		}
+		return g.resolveExpr(parent, call.Expressions[parts[1]], seen)
+	case "module":
+		if len(parts) < 2 {
+			return nil
+		}
+		child := joinAddress(path, "module."+parts[1])
+		m := g.idx.modules[child]
+		if m == nil {
+			return nil
+		}
+		if len(parts) >= 3 {
+			if out := m.Outputs[parts[2]]; out != nil {
+				return g.resolveExpr(child, out.Expression, seen)
+			}
+			return nil
+		}
+		// The whole module (depends_on = [module.net]): all its resources.
+		var out []string
+		prefix := child + "."
+		for addr := range g.idx.resources {
+			if strings.HasPrefix(addr, prefix) {
+				out = append(out, addr)
+			}
+		}
+		return out
+	}
+	addr := resolveReference(path, ref)
+	if g.idx.resources[addr] == nil {
+		return nil
+	}
+	return []string{addr}
+}
+
+func (g *depGraph) resolveExpr(path string, expr *tfjson.Expression, seen map[string]bool) []string {
+	var out []string
+	for _, ref := range expressionRefs(expr) {
+		out = append(out, g.resolve(path, ref, seen)...)
+	}
+	return out
+}
+
+// downstream returns the resources that depend on addr, directly or
+// transitively, with the length of the longest shortest path to them.
+func (g *depGraph) downstream(addr string) ([]string, int) {
+	depth := map[string]int{addr: 0}
+	queue := []string{addr}
+	maxDepth := 0
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for next := range g.dependents[cur] {
+			if _, ok := depth[next]; ok {
+				continue
+			}
+			depth[next] = depth[cur] + 1
+			if depth[next] > maxDepth {
+				maxDepth = depth[next]
+			}
+			queue = append(queue, next)
+		}
+	}
+	delete(depth, addr)
+	out := make([]string, 0, len(depth))
+	for a := range depth {
+		out = append(out, a)
+	}
+	sort.Strings(out)
+	return out, maxDepth
+}
+
+// expressionRefs collects the references of an expression and of the
+// nested blocks inside it.
+func expressionRefs(expr *tfjson.Expression) []string {
+	if expr == nil || expr.ExpressionData == nil {
+		return nil
+	}
+	refs := append([]string{}, expr.References...)
+	for _, block := range expr.NestedBlocks {
+		for _, nested := range block {
+			refs = append(refs, expressionRefs(nested)...)
+		}
+	}
+	return refs
+}
+
+// extractBlastRadius lists, for each delete and replace, the resources
+// in configuration that depend on it and would be affected. Dependents
+// are configuration addresses, not limited by the scope filters: a
+// change in scope can break resources outside it. Each dependents list
+// is capped at max; the bool reports whether any of them was cut.
+func extractBlastRadius(changes []*tfjson.ResourceChange, g *depGraph, max int) ([]map[string]any, int, bool) {
+	out := []map[string]any{}
+	maxDepth := 0
+	anyTruncated := false
+	for _, rc := range changes {
+		action := primaryAction(rc.Change.Actions)
+		if action != "delete" && action != "replace" {
+			continue
+		}
+		dependents, depth := g.downstream(staticAddress(rc))
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		count := len(dependents)
+		dependents, _, truncated := truncate(dependents, max)
+		anyTruncated = anyTruncated || truncated
+		out = append(out, map[string]any{
+			"address":              rc.Address,
+			"type":                 rc.Type,
+			"action":               action,
+			"dependents":           dependents,
+			"dependent_count":      count,
+			"dependents_truncated": truncated,
+			"depth":                depth,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i]["address"].(string) < out[j]["address"].(string)
+	})
+	return out, maxDepth, anyTruncated
+}
@@ -0,0 +1,87 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_BlastRadius(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "dependency_graph.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := map[string]map[string]any{}
+	for _, e := range result.Input["blast_radius"].([]map[string]any) {
+		entries[e["address"].(string)] = e
+	}
+	if len(entries) != 3 {
+		t.Fatalf("blast_radius: expected 3 entries (deletes and replaces only), got %d", len(entries))
+	}
+
+	subnet := entries["aws_subnet.private"]
+	assertStr(t, "subnet.action", "replace", subnet["action"])
+	want := []string{
+		"aws_cloudwatch_metric_alarm.cpu",               // depends_on = [module.app]
+		"aws_route53_record.app",                        // module.app.instance_ip output
+		"aws_security_group.db",                         // reference in a nested block
+		"module.app.aws_instance.web",                   // var.subnet_id
+		"module.app.aws_lb_target_group_attachment.web", // via aws_instance.web
+	}
+	if got := subnet["dependents"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("subnet.dependents:\n got %v\nwant %v", got, want)
+	}
+	assertInt(t, "subnet.dependent_count", 5, subnet["dependent_count"])
+	assertInt(t, "subnet.depth", 2, subnet["depth"])
+
+	// Nothing refers to the bucket; the role's block is gone from configuration.
+	for _, addr := range []string{"aws_s3_bucket.logs", "aws_iam_role.legacy"} {
+		if got := entries[addr]["dependents"]; !reflect.DeepEqual(got, []string{}) {
+			t.Errorf("%s.dependents: expected empty, got %v", addr, got)
+		}
+		assertInt(t, addr+".depth", 0, entries[addr]["depth"])
+	}
+
+	assertInt(t, "blast_radius_max_depth", 2, result.Input["blast_radius_max_depth"])
+}
+
+func TestPlanAdapter_BlastRadius_DependentsTruncated(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "dependency_graph.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "aws_subnet",
+		"max_resource_changes":  "2",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := result.Input["blast_radius"].([]map[string]any)
+	if len(entries) != 1 {
+		t.Fatalf("blast_radius: expected only the subnet in scope, got %d", len(entries))
+	}
+	// Dependents outside the scope filter are still reported.
+	assertInt(t, "dependent_count", 5, entries[0]["dependent_count"])
+	assertBool(t, "dependents_truncated", true, entries[0]["dependents_truncated"])
+	if n := len(entries[0]["dependents"].([]string)); n != 2 {
+		t.Errorf("dependents: expected 2 after truncation, got %d", n)
+	}
+
+	const want = "blast_radius dependents truncated: showing at most 2 per entry"
+	found := false
+	for _, w := range result.Metadata["warnings"].([]string) {
+		if w == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %q warning, got %v", want, result.Metadata["warnings"])
+	}
+}
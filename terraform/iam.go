@@ -0,0 +1,302 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// iamPolicyAttributes maps IAM resource types to the attribute holding
+// their JSON policy document.
+var iamPolicyAttributes = map[string]string{
+	"aws_iam_policy":       "policy",
+	"aws_iam_role_policy":  "policy",
+	"aws_iam_user_policy":  "policy",
+	"aws_iam_group_policy": "policy",
+}
+
+// policyStatement is one normalized IAM policy statement. Action,
+// Resource and Principal accept either a string or a list in IAM JSON;
+// here they are always sorted lists, and principals are keyed by type.
+type policyStatement struct {
+	Sid           string              `json:"sid"`
+	Effect        string              `json:"effect"`
+	Actions       []string            `json:"actions"`
+	NotActions    []string            `json:"not_actions"`
+	Resources     []string            `json:"resources"`
+	NotResources  []string            `json:"not_resources"`
+	Principals    map[string][]string `json:"principals"`
+	NotPrincipals map[string][]string `json:"not_principals"`
+	Conditions    []policyCondition   `json:"conditions"`
+}
+
+type policyCondition struct {
+	Operator string   `json:"operator"`
+	Key      string   `json:"key"`
+	Values   []string `json:"values"`
+}
+
+func (s policyStatement) fields() map[string]any {
+	return map[string]any{
+		"sid":            s.Sid,
+		"effect":         s.Effect,
+		"actions":        s.Actions,
+		"not_actions":    s.NotActions,
+		"resources":      s.Resources,
+		"not_resources":  s.NotResources,
+		"principals":     s.Principals,
+		"not_principals": s.NotPrincipals,
+		"conditions":     conditionFields(s.Conditions),
+	}
+}
+
+func conditionFields(conditions []policyCondition) []map[string]any {
+	out := make([]map[string]any, 0, len(conditions))
+	for _, c := range conditions {
+		out = append(out, map[string]any{
+			"operator": c.Operator,
+			"key":      c.Key,
+			"values":   c.Values,
+		})
+	}
+	return out
+}
+
+// extractIAMPolicyStatements parses the policy documents of in-scope IAM
+// policy resources and aws_iam_policy_document data sources. Identical
+// statements on the same resource are emitted once. Documents that fail
+// to parse produce a warning instead of an error so one malformed policy
+// does not hide the rest of the plan.
+func extractIAMPolicyStatements(changes []*tfjson.ResourceChange) ([]map[string]any, []string) {
+	out := []map[string]any{}
+	var warnings []string
+	seen := map[string]bool{}
+
+	for _, rc := range changes {
+		after := asMap(rc.Change.After)
+		if after == nil {
+			continue
+		}
+
+		var statements []policyStatement
+		if attr, ok := iamPolicyAttributes[rc.Type]; ok {
+			doc, known := after[attr].(string)
+			if !known || doc == "" {
+				continue
+			}
+			parsed, err := parsePolicyDocument(doc)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: cannot parse %s: %v", rc.Address, attr, err))
+				continue
+			}
+			statements = parsed
+		} else if rc.Type == "aws_iam_policy_document" {
+			statements = policyDocumentStatements(after)
+			if statements == nil {
+				continue
+			}
+		} else {
+			continue
+		}
+
+		action := primaryAction(rc.Change.Actions)
+		for _, st := range statements {
+			b, _ := json.Marshal(st)
+			key := rc.Address + "\x00" + string(b)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			entry := st.fields()
+			entry["address"] = rc.Address
+			entry["resource_type"] = rc.Type
+			entry["action"] = action
+			out = append(out, entry)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i]["address"].(string) < out[j]["address"].(string)
+	})
+	return out, warnings
+}
+
+// policyDocumentStatements reads an aws_iam_policy_document data source.
+// The rendered json attribute is preferred; when it is unknown at plan
+// time the statement blocks are normalized directly. Returns nil when
+// neither is available.
+func policyDocumentStatements(after map[string]any) []policyStatement {
+	if doc, ok := after["json"].(string); ok && doc != "" {
+		if parsed, err := parsePolicyDocument(doc); err == nil {
+			return parsed
+		}
+	}
+	blocks := attrBlocks(after, "statement")
+	if blocks == nil {
+		return nil
+	}
+	statements := make([]policyStatement, 0, len(blocks))
+	for _, b := range blocks {
+		st := policyStatement{
+			Sid:           attrString(b, "sid"),
+			Effect:        attrString(b, "effect"),
+			Actions:       sortedStrings(attrStrings(b, "actions")),
+			NotActions:    sortedStrings(attrStrings(b, "not_actions")),
+			Resources:     sortedStrings(attrStrings(b, "resources")),
+			NotResources:  sortedStrings(attrStrings(b, "not_resources")),
+			Principals:    principalBlocks(attrBlocks(b, "principals")),
+			NotPrincipals: principalBlocks(attrBlocks(b, "not_principals")),
+			Conditions:    []policyCondition{},
+		}
+		if st.Effect == "" {
+			st.Effect = "Allow" // data source default
+		}
+		for _, c := range attrBlocks(b, "condition") {
+			st.Conditions = append(st.Conditions, policyCondition{
+				Operator: attrString(c, "test"),
+				Key:      attrString(c, "variable"),
+				Values:   sortedStrings(attrStrings(c, "values")),
+			})
+		}
+		sortConditions(st.Conditions)
+		statements = append(statements, st)
+	}
+	return statements
+}
+
+func principalBlocks(blocks []map[string]any) map[string][]string {
+	out := map[string][]string{}
+	for _, b := range blocks {
+		typ := attrString(b, "type")
+		if typ == "*" {
+			typ = "AWS"
+		}
+		out[typ] = sortedStrings(append(out[typ], attrStrings(b, "identifiers")...))
+	}
+	return out
+}
+
+// parsePolicyDocument decodes an IAM JSON policy document into
+// normalized statements.
+func parsePolicyDocument(doc string) ([]policyStatement, error) {
+	var raw struct {
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(doc), &raw); err != nil {
+		return nil, err
+	}
+
+	// Statement may be a single object or a list of objects.
+	type rawStatement struct {
+		Sid          string                    `json:"Sid"`
+		Effect       string                    `json:"Effect"`
+		Action       any                       `json:"Action"`
+		NotAction    any                       `json:"NotAction"`
+		Resource     any                       `json:"Resource"`
+		NotResource  any                       `json:"NotResource"`
+		Principal    any                       `json:"Principal"`
+		NotPrincipal any                       `json:"NotPrincipal"`
+		Condition    map[string]map[string]any `json:"Condition"`
+	}
+	var list []rawStatement
+	trimmed := strings.TrimSpace(string(raw.Statement))
+	switch {
+	case trimmed == "" || trimmed == "null":
+		return []policyStatement{}, nil
+	case strings.HasPrefix(trimmed, "{"):
+		var one rawStatement
+		if err := json.Unmarshal(raw.Statement, &one); err != nil {
+			return nil, err
+		}
+		list = []rawStatement{one}
+	default:
+		if err := json.Unmarshal(raw.Statement, &list); err != nil {
+			return nil, err
+		}
+	}
+
+	statements := make([]policyStatement, 0, len(list))
+	for _, r := range list {
+		st := policyStatement{
+			Sid:           r.Sid,
+			Effect:        r.Effect,
+			Actions:       stringOrList(r.Action),
+			NotActions:    stringOrList(r.NotAction),
+			Resources:     stringOrList(r.Resource),
+			NotResources:  stringOrList(r.NotResource),
+			Principals:    principalMap(r.Principal),
+			NotPrincipals: principalMap(r.NotPrincipal),
+			Conditions:    []policyCondition{},
+		}
+		for op, kv := range r.Condition {
+			for key, values := range kv {
+				st.Conditions = append(st.Conditions, policyCondition{
+					Operator: op,
+					Key:      key,
+					Values:   stringOrList(values),
+				})
+			}
+		}
+		sortConditions(st.Conditions)
+		statements = append(statements, st)
+	}
+	return statements, nil
+}
+
+// principalMap normalizes a Principal element. The bare "*" form is
+// equivalent to {"AWS": "*"}.
+func principalMap(v any) map[string][]string {
+	out := map[string][]string{}
+	switch p := v.(type) {
+	case string:
+		if p != "" {
+			out["AWS"] = []string{p}
+		}
+	case map[string]any:
+		for typ, ids := range p {
+			out[typ] = stringOrList(ids)
+		}
+	}
+	return out
+}
+
+// stringOrList normalizes IAM's "string or list of strings" values into
+// a sorted list. Non-string scalars (booleans, numbers in conditions)
+// are rendered with their JSON form.
+func stringOrList(v any) []string {
+	var out []string
+	switch x := v.(type) {
+	case nil:
+	case string:
+		out = []string{x}
+	case []any:
+		for _, item := range x {
+			out = append(out, stringOrList(item)...)
+		}
+	default:
+		b, _ := json.Marshal(x)
+		out = []string{string(b)}
+	}
+	return sortedStrings(out)
+}
+
+func sortedStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	sort.Strings(s)
+	return s
+}
+
+func sortConditions(c []policyCondition) {
+	sort.Slice(c, func(i, j int) bool {
+		if c[i].Operator != c[j].Operator {
+			return c[i].Operator < c[j].Operator
+		}
+		return c[i].Key < c[j].Key
+	})
+}
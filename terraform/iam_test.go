@@ -0,0 +1,112 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_IAMPolicyStatements(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_iam.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// aws_iam.json: admin has 3 statements (2 identical), reader has 1,
+	// deploy's policy is unknown, broken does not parse, and the policy
+	// document data source has 1 statement block.
+	statements := result.Input["iam_policy_statements"].([]map[string]any)
+	assertInt(t, "iam_policy_statements_total", 4, result.Input["iam_policy_statements_total"])
+	assertBool(t, "iam_policy_statements_truncated", false, result.Input["iam_policy_statements_truncated"])
+	if len(statements) != 4 {
+		t.Fatalf("expected 4 statements, got %d: %v", len(statements), statements)
+	}
+
+	byAddress := map[string][]map[string]any{}
+	for _, s := range statements {
+		addr := s["address"].(string)
+		byAddress[addr] = append(byAddress[addr], s)
+	}
+
+	admin := byAddress["aws_iam_policy.admin"]
+	if len(admin) != 2 {
+		t.Fatalf("expected duplicate admin statement to be deduplicated, got %d", len(admin))
+	}
+	assertStr(t, "admin.effect", "Allow", admin[0]["effect"])
+	if !reflect.DeepEqual(admin[0]["actions"], []string{"*"}) {
+		t.Errorf("admin.actions: got %v", admin[0]["actions"])
+	}
+	assertStr(t, "admin.action", "create", admin[0]["action"])
+	deny := admin[1]
+	assertStr(t, "deny.sid", "DenyBilling", deny["sid"])
+	if !reflect.DeepEqual(deny["not_actions"], []string{"aws-portal:*", "s3:GetObject"}) {
+		t.Errorf("deny.not_actions: got %v", deny["not_actions"])
+	}
+	if !reflect.DeepEqual(deny["resources"], []string{"arn:aws:s3:::a/*", "arn:aws:s3:::b/*"}) {
+		t.Errorf("deny.resources: got %v", deny["resources"])
+	}
+
+	reader := byAddress["aws_iam_role_policy.reader"]
+	if len(reader) != 1 {
+		t.Fatalf("expected 1 reader statement, got %d", len(reader))
+	}
+	conditions := reader[0]["conditions"].([]map[string]any)
+	if len(conditions) != 2 {
+		t.Fatalf("expected 2 reader conditions, got %v", conditions)
+	}
+	assertStr(t, "conditions[0].operator", "Bool", conditions[0]["operator"])
+	if !reflect.DeepEqual(conditions[0]["values"], []string{"true"}) {
+		t.Errorf("conditions[0].values: got %v", conditions[0]["values"])
+	}
+	assertStr(t, "conditions[1].key", "aws:PrincipalOrgID", conditions[1]["key"])
+
+	doc := byAddress["data.aws_iam_policy_document.assume"]
+	if len(doc) != 1 {
+		t.Fatalf("expected policy document statement even with data sources excluded, got %d", len(doc))
+	}
+	assertStr(t, "doc.effect", "Allow", doc[0]["effect"])
+	assertStr(t, "doc.action", "read", doc[0]["action"])
+	principals := doc[0]["principals"].(map[string][]string)
+	if !reflect.DeepEqual(principals["Service"], []string{"ec2.amazonaws.com"}) {
+		t.Errorf("doc.principals: got %v", principals)
+	}
+
+	// Data sources stay out of counts and types.
+	for _, typ := range result.Input["resource_types"].([]string) {
+		if typ == "aws_iam_policy_document" {
+			t.Error("aws_iam_policy_document must not appear in resource_types by default")
+		}
+	}
+
+	warnings := result.Metadata["warnings"].([]string)
+	found := false
+	for _, w := range warnings {
+		if containsStr(w, "aws_iam_group_policy.broken") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected parse warning for aws_iam_group_policy.broken, got %v", warnings)
+	}
+}
+
+func TestPlanAdapter_IAMPolicyStatements_Truncation(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_iam.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw,
+		map[string]string{"max_resource_changes": "2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(result.Input["iam_policy_statements"].([]map[string]any)); n != 2 {
+		t.Errorf("expected 2 statements after truncation, got %d", n)
+	}
+	assertInt(t, "iam_policy_statements_total", 4, result.Input["iam_policy_statements_total"])
+	assertBool(t, "iam_policy_statements_truncated", true, result.Input["iam_policy_statements_truncated"])
+}
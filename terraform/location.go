@@ -0,0 +1,173 @@
+package terraform
+
+import (
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// locations collects where the changes of a plan land. Only resources
+// that are actually changed contribute, so an untouched resource in
+// another region does not widen the blast radius.
+type locations struct {
+	regions       map[string]bool
+	accounts      map[string]bool // AWS
+	projects      map[string]bool // GCP
+	subscriptions map[string]bool // Azure
+}
+
+// extractLocations derives regions, AWS accounts, GCP projects and Azure
+// subscriptions from each changed resource's attributes (region,
+// location, zone, project, ARNs, Azure resource IDs) and from the
+// constant settings of the provider configuration it uses. Most AWS
+// resources carry no region attribute and have no ARN until created, so
+// the provider configuration is often the only source.
+func extractLocations(changes []*tfjson.ResourceChange, cfg *tfjson.Config, idx *configIndex) locations {
+	loc := locations{
+		regions:       map[string]bool{},
+		accounts:      map[string]bool{},
+		projects:      map[string]bool{},
+		subscriptions: map[string]bool{},
+	}
+	for _, rc := range changes {
+		switch primaryAction(rc.Change.Actions) {
+		case "create", "update", "delete", "replace":
+		default:
+			continue
+		}
+		values := asMap(rc.Change.After)
+		if values == nil {
+			values = asMap(rc.Change.Before)
+		}
+		loc.addAttributes(rc.Type, values)
+		if pc := providerConfigFor(rc, cfg, idx); pc != nil {
+			loc.addProviderConfig(pc)
+		}
+	}
+	return loc
+}
+
+func (loc locations) addAttributes(resourceType string, values map[string]any) {
+	add := func(set map[string]bool, v string) {
+		if v != "" {
+			set[v] = true
+		}
+	}
+	switch {
+	case strings.HasPrefix(resourceType, "aws_"):
+		add(loc.regions, attrString(values, "region"))
+		arn := attrString(values, "arn")
+		add(loc.regions, arnRegion(arn))
+		add(loc.accounts, arnAccount(arn))
+	case strings.HasPrefix(resourceType, "google_"):
+		add(loc.regions, attrString(values, "region"))
+		add(loc.regions, attrString(values, "location"))
+		add(loc.regions, zoneRegion(attrString(values, "zone")))
+		add(loc.projects, attrString(values, "project"))
+	case strings.HasPrefix(resourceType, "azurerm_"):
+		add(loc.regions, attrString(values, "location"))
+		add(loc.subscriptions, azureSubscription(attrString(values, "id")))
+	case strings.HasPrefix(resourceType, "hcloud_"):
+		add(loc.regions, attrString(values, "location"))
+		// Datacenters are named after their location: fsn1-dc14.
+		dc, _, _ := strings.Cut(attrString(values, "datacenter"), "-")
+		add(loc.regions, dc)
+	}
+}
+
+func (loc locations) addProviderConfig(pc *tfjson.ProviderConfig) {
+	if s, ok := constantString(pc.Expressions["region"]); ok && s != "" {
+		loc.regions[s] = true
+	}
+	switch pc.Name {
+	case "aws":
+		for _, acct := range providerAccounts(pc) {
+			loc.accounts[acct] = true
+		}
+	case "google", "google-beta":
+		if s, ok := constantString(pc.Expressions["project"]); ok && s != "" {
+			loc.projects[s] = true
+		}
+	case "azurerm":
+		if s, ok := constantString(pc.Expressions["subscription_id"]); ok && s != "" {
+			loc.subscriptions[s] = true
+		}
+	}
+}
+
+// providerAccounts returns the AWS accounts a provider configuration
+// works in, from the constant assume_role.role_arn and
+// allowed_account_ids.
+func providerAccounts(pc *tfjson.ProviderConfig) []string {
+	var out []string
+	if pc == nil || pc.Name != "aws" {
+		return out
+	}
+	if expr := pc.Expressions["assume_role"]; expr != nil && expr.ExpressionData != nil {
+		for _, block := range expr.NestedBlocks {
+			if arn, ok := constantString(block["role_arn"]); ok && arnAccount(arn) != "" {
+				out = append(out, arnAccount(arn))
+			}
+		}
+	}
+	for _, acct := range constantStrings(pc.Expressions["allowed_account_ids"]) {
+		if isAccountID(acct) {
+			out = append(out, acct)
+		}
+	}
+	return out
+}
+
+// providerConfigFor returns the provider configuration a resource change
+// uses. provider_config_key does not always name an entry of
+// provider_config (providers passed into modules are keyed by the
+// module, "module.net:aws"), so it falls back to the key without its
+// module prefix and then to the provider's default configuration, which
+// also covers deletes whose configuration block is gone.
+func providerConfigFor(rc *tfjson.ResourceChange, cfg *tfjson.Config, idx *configIndex) *tfjson.ProviderConfig {
+	if cfg == nil {
+		return nil
+	}
+	var keys []string
+	if cr := idx.lookup(rc); cr != nil && cr.ProviderConfigKey != "" {
+		key := cr.ProviderConfigKey
+		keys = append(keys, key, key[strings.LastIndex(key, ":")+1:])
+	}
+	keys = append(keys, rc.ProviderName[strings.LastIndex(rc.ProviderName, "/")+1:])
+	for _, key := range keys {
+		if pc := cfg.ProviderConfigs[key]; pc != nil {
+			return pc
+		}
+	}
+	return nil
+}
+
+// arnRegion returns the region field of an ARN, "" for global services
+// such as IAM.
+func arnRegion(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[3]
+}
+
+// zoneRegion maps a GCP zone (europe-west1-b) to its region.
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return ""
+}
+
+// azureSubscription returns the subscription of an Azure resource ID
+// (/subscriptions/<id>/resourceGroups/...), or "".
+func azureSubscription(id string) string {
+	parts := strings.Split(id, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "subscriptions") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
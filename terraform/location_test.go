@@ -0,0 +1,56 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_Locations(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "multi_cloud.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string][]string{
+		// eu-central-1: default aws provider (aws_instance.web has no region
+		// attribute). us-west-2: SQS ARN; the aws.west region is a variable.
+		// us-east1 from the GCP zone, europe-west1 from the google provider.
+		"regions": {"eu-central-1", "europe-west1", "fsn1", "hel1", "us-east1", "us-west-2", "westeurope"},
+		// 111…: SQS ARN, 222…: assume_role. The no-op role's account is not listed.
+		"accounts":      {"111111111111", "222222222222"},
+		"projects":      {"analytics-prod", "shared-infra"},
+		"subscriptions": {"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"},
+	}
+	for field, values := range want {
+		if got := result.Input[field]; !reflect.DeepEqual(got, values) {
+			t.Errorf("%s:\n got %v\nwant %v", field, got, values)
+		}
+	}
+}
+
+func TestPlanAdapter_Locations_Scoped(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "multi_cloud.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "hcloud_server",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := result.Input["regions"]; !reflect.DeepEqual(got, []string{"fsn1"}) {
+		t.Errorf("regions: got %v", got)
+	}
+	for _, field := range []string{"accounts", "projects", "subscriptions"} {
+		if got := result.Input[field]; !reflect.DeepEqual(got, []string{}) {
+			t.Errorf("%s: expected empty, got %v", field, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package terraform
+
+import tfjson "github.com/hashicorp/terraform-json"
+
+// Plan JSON does not record the mode the plan was created in, so
+// planMode infers it from the plan's shape:
+//
+//   - refresh-only: Terraform plans no resource changes at all, while a
+//     normal plan lists every resource, unchanged ones as no-op. So an
+//     empty resource_changes with resources in prior state, or with
+//     drift, is a refresh-only plan.
+//   - destroy: every managed resource change is a delete.
+//   - normal: everything else, including plans with nothing to do.
+//
+// The inference looks at the whole plan, not the configured scope.
+func planMode(plan *tfjson.Plan) string {
+	if len(plan.ResourceChanges) == 0 {
+		if len(plan.ResourceDrift) > 0 || stateHasResources(plan.PriorState) {
+			return "refresh-only"
+		}
+		return "normal"
+	}
+	deletes := 0
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode != tfjson.ManagedResourceMode || rc.Change == nil {
+			continue
+		}
+		if !rc.Change.Actions.Delete() {
+			return "normal"
+		}
+		deletes++
+	}
+	if deletes > 0 {
+		return "destroy"
+	}
+	return "normal"
+}
+
+func stateHasResources(state *tfjson.State) bool {
+	if state == nil || state.Values == nil {
+		return false
+	}
+	var walk func(m *tfjson.StateModule) bool
+	walk = func(m *tfjson.StateModule) bool {
+		if m == nil {
+			return false
+		}
+		for _, r := range m.Resources {
+			if r.Mode == tfjson.ManagedResourceMode {
+				return true
+			}
+		}
+		for _, child := range m.ChildModules {
+			if walk(child) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(state.Values.RootModule)
+}
+
+// planApplyable reports whether applying the plan would do anything.
+// Terraform 1.7+ records it; for older plans it is derived the same way:
+// not errored, and some resource or output change that is not a no-op,
+// or drift to persist in a refresh-only plan.
+func planApplyable(plan *tfjson.Plan, extras planExtras, mode string) bool {
+	if extras.Applyable != nil {
+		return *extras.Applyable
+	}
+	if extras.Errored {
+		return false
+	}
+	if mode == "refresh-only" {
+		return len(plan.ResourceDrift) > 0
+	}
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change != nil && !rc.Change.Actions.NoOp() {
+			return true
+		}
+	}
+	for _, c := range plan.OutputChanges {
+		if c != nil && !c.Actions.NoOp() {
+			return true
+		}
+	}
+	return false
+}
+
+// planComplete reports whether the plan covers every resource. Terraform
+// 1.8+ records it; older plans are complete unless they errored or
+// deferred changes.
+func planComplete(plan *tfjson.Plan, extras planExtras) bool {
+	if plan.Complete != nil {
+		return *plan.Complete
+	}
+	return !extras.Errored && len(plan.DeferredChanges) == 0
+}
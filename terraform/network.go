@@ -0,0 +1,202 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// exposure is one way a resource is reachable from the internet: a
+// public load balancer, a public IP, or a firewall rule open to any
+// source. Rules are split per protocol and port range.
+type exposure struct {
+	address      string
+	resourceType string
+	action       string
+	kind         string
+	protocol     string
+	ports        string
+	sources      []string
+	isNew        bool
+}
+
+func (e exposure) key() string {
+	return e.kind + "|" + e.protocol + "|" + e.ports
+}
+
+func (e exposure) fields() map[string]any {
+	return map[string]any{
+		"address":       e.address,
+		"resource_type": e.resourceType,
+		"action":        e.action,
+		"kind":          e.kind,
+		"protocol":      e.protocol,
+		"ports":         e.ports,
+		"sources":       e.sources,
+		"new":           e.isNew,
+	}
+}
+
+// extractNetworkExposure lists what the planned (after) state exposes
+// to the internet, sorted by address. An exposure is new when the
+// resource's before state did not have it: always for creates, and for
+// updates and replaces that open something. Deletes are skipped, and so
+// are values unknown until apply.
+func extractNetworkExposure(changes []*tfjson.ResourceChange) []map[string]any {
+	var all []exposure
+	for _, rc := range changes {
+		after := asMap(rc.Change.After)
+		if after == nil {
+			continue
+		}
+		action := primaryAction(rc.Change.Actions)
+		before := map[string]bool{}
+		for _, e := range exposures(rc.Type, asMap(rc.Change.Before), nil) {
+			before[e.key()] = true
+		}
+		for _, e := range exposures(rc.Type, after, asMap(rc.Change.AfterUnknown)) {
+			e.address, e.resourceType, e.action = rc.Address, rc.Type, action
+			e.isNew = !before[e.key()]
+			all = append(all, e)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].address < all[j].address
+	})
+	out := make([]map[string]any, 0, len(all))
+	for _, e := range all {
+		out = append(out, e.fields())
+	}
+	return out
+}
+
+// exposures reads the exposures of one resource state. unknown is the
+// after_unknown tree (nil for the before state).
+func exposures(typ string, m, unknown map[string]any) []exposure {
+	if m == nil {
+		return nil
+	}
+	switch typ {
+	case "aws_lb", "aws_alb", "aws_elb":
+		// internal defaults to false: a load balancer is public unless
+		// it says otherwise. Left out, it is unknown until apply and
+		// still public.
+		if internal, _ := attrBool(m, "internal"); !internal || unknown["internal"] == true {
+			return []exposure{{kind: "load_balancer", sources: []string{}}}
+		}
+
+	case "aws_eip":
+		return []exposure{{kind: "public_ip", sources: []string{}}}
+
+	case "aws_instance":
+		if public, _ := attrBool(m, "associate_public_ip_address"); public {
+			return []exposure{{kind: "public_ip", sources: []string{}}}
+		}
+
+	case "google_compute_firewall":
+		if disabled, _ := attrBool(m, "disabled"); disabled {
+			return nil
+		}
+		if d := attrString(m, "direction"); d != "" && !strings.EqualFold(d, "INGRESS") {
+			return nil
+		}
+		ranges := attrStrings(m, "source_ranges")
+		if len(ranges) == 0 && !hasSourceFilter(m, unknown) {
+			// GCP applies 0.0.0.0/0 to an ingress rule without sources.
+			ranges = []string{"0.0.0.0/0"}
+		}
+		sources := worldOpen(ranges)
+		if len(sources) == 0 {
+			return nil
+		}
+		var out []exposure
+		for _, allow := range attrBlocks(m, "allow") {
+			protocol := normalizeProtocol(attrString(allow, "protocol"))
+			ports := attrStrings(allow, "ports")
+			if len(ports) == 0 {
+				ports = []string{"all"}
+			}
+			for _, p := range ports {
+				out = append(out, exposure{kind: "firewall_rule", protocol: protocol, ports: p, sources: sources})
+			}
+		}
+		return out
+
+	case "azurerm_network_security_rule":
+		if !strings.EqualFold(attrString(m, "direction"), "Inbound") ||
+			!strings.EqualFold(attrString(m, "access"), "Allow") {
+			return nil
+		}
+		sources := worldOpen(append(nonEmpty(attrString(m, "source_address_prefix")),
+			attrStrings(m, "source_address_prefixes")...))
+		if len(sources) == 0 {
+			return nil
+		}
+		protocol := normalizeProtocol(attrString(m, "protocol"))
+		if protocol == "*" {
+			protocol = "all"
+		}
+		ports := append(nonEmpty(attrString(m, "destination_port_range")),
+			attrStrings(m, "destination_port_ranges")...)
+		var out []exposure
+		for _, p := range ports {
+			if p == "*" {
+				p = "all"
+			}
+			out = append(out, exposure{kind: "firewall_rule", protocol: protocol, ports: p, sources: sources})
+		}
+		return out
+
+	case "hcloud_firewall":
+		var out []exposure
+		for _, rule := range attrBlocks(m, "rule") {
+			if attrString(rule, "direction") != "in" {
+				continue
+			}
+			sources := worldOpen(attrStrings(rule, "source_ips"))
+			if len(sources) == 0 {
+				continue
+			}
+			// ICMP, GRE and ESP rules have no port.
+			ports := attrString(rule, "port")
+			if ports == "" || ports == "any" {
+				ports = "all"
+			}
+			out = append(out, exposure{
+				kind:     "firewall_rule",
+				protocol: normalizeProtocol(attrString(rule, "protocol")),
+				ports:    ports,
+				sources:  sources,
+			})
+		}
+		return out
+	}
+	return nil
+}
+
+// hasSourceFilter reports whether a google_compute_firewall selects its
+// sources by network tag or service account, known or not.
+func hasSourceFilter(m, unknown map[string]any) bool {
+	for _, key := range []string{"source_tags", "source_service_accounts"} {
+		if len(attrStrings(m, key)) > 0 || unknown[key] == true {
+			return true
+		}
+	}
+	return false
+}
+
+// worldOpen returns the sources that mean "anywhere on the internet".
+// Azure spells it *, Internet or Any as well as the CIDRs.
+func worldOpen(sources []string) []string {
+	out := []string{}
+	for _, s := range sources {
+		switch strings.ToLower(s) {
+		case "0.0.0.0/0", "::/0", "*", "internet", "any":
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,103 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_NetworkExposure(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "network_exposure.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type row struct {
+		address, kind, protocol, ports string
+		isNew                          bool
+	}
+	var got []row
+	for _, e := range result.Input["network_exposure"].([]map[string]any) {
+		got = append(got, row{
+			e["address"].(string), e["kind"].(string), e["protocol"].(string), e["ports"].(string), e["new"].(bool),
+		})
+	}
+	want := []row{
+		{"aws_eip.nat", "public_ip", "", "", true},
+		// Already public before the update.
+		{"aws_instance.bastion", "public_ip", "", "", false},
+		// internal left out: unknown until apply, defaults to false.
+		{"aws_lb.pending", "load_balancer", "", "", true},
+		{"aws_lb.public", "load_balancer", "", "", true},
+		{"azurerm_network_security_rule.rdp", "firewall_rule", "tcp", "3389", true},
+		// No source ranges, tags or service accounts: GCP applies 0.0.0.0/0.
+		{"google_compute_firewall.default_source", "firewall_rule", "tcp", "22", true},
+		{"google_compute_firewall.ssh", "firewall_rule", "tcp", "22", true},
+		{"google_compute_firewall.ssh", "firewall_rule", "tcp", "80-443", true},
+		// 22 was open to 10.0.0.0/8 only; 443 was already world-open.
+		{"hcloud_firewall.web", "firewall_rule", "tcp", "22", true},
+		{"hcloud_firewall.web", "firewall_rule", "tcp", "443", false},
+		{"hcloud_firewall.web", "firewall_rule", "icmp", "all", true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("network_exposure:\n got %v\nwant %v", got, want)
+	}
+	assertBool(t, "has_new_network_exposure", true, result.Input["has_new_network_exposure"])
+}
+
+func TestPlanAdapter_NetworkExposure_Sources(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "network_exposure.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "hcloud_firewall",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := result.Input["network_exposure"].([]map[string]any)
+	if len(entries) != 3 {
+		t.Fatalf("network_exposure: expected 3 hcloud entries, got %d", len(entries))
+	}
+	if got, want := entries[0]["sources"], []string{"0.0.0.0/0", "::/0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sources: expected %v, got %v", want, got)
+	}
+	assertStr(t, "resource_type", "hcloud_firewall", entries[0]["resource_type"])
+	assertStr(t, "action", "update", entries[0]["action"])
+}
+
+func TestPlanAdapter_NetworkExposure_NothingNew(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "network_exposure.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "aws_instance",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertInt(t, "network_exposure_total", 1, result.Input["network_exposure_total"])
+	assertBool(t, "has_new_network_exposure", false, result.Input["has_new_network_exposure"])
+}
+
+func TestPlanAdapter_NetworkExposure_Truncated(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "network_exposure.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"max_resource_changes": "2",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertInt(t, "network_exposure_total", 11, result.Input["network_exposure_total"])
+	assertBool(t, "network_exposure_truncated", true, result.Input["network_exposure_truncated"])
+	// Computed before truncation.
+	assertBool(t, "has_new_network_exposure", true, result.Input["has_new_network_exposure"])
+}
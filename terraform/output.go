@@ -0,0 +1,39 @@
+package terraform
+
+import "fmt"
+
+// output collects the input fields and warnings of one conversion.
+type output struct {
+	input    map[string]any
+	warnings []string
+	// max is max_resource_changes, the cap on every array.
+	max int
+}
+
+func newOutput(max int) *output {
+	return &output{input: map[string]any{}, warnings: []string{}, max: max}
+}
+
+func (o *output) set(fields map[string]any) {
+	for k, v := range fields {
+		o.input[k] = v
+	}
+}
+
+func (o *output) warn(format string, args ...any) {
+	o.warnings = append(o.warnings, fmt.Sprintf(format, args...))
+}
+
+// addCapped sets name to s capped at max_resource_changes, along with
+// name_total and name_truncated, and warns when entries were dropped.
+// It returns the total before truncation, for shortcuts and counts.
+func addCapped[T any](o *output, name string, s []T) int {
+	s, total, truncated := truncate(s, o.max)
+	o.input[name] = s
+	o.input[name+"_total"] = total
+	o.input[name+"_truncated"] = truncated
+	if truncated {
+		o.warn("%s truncated: showing %d of %d", name, len(s), total)
+	}
+	return total
+}
@@ -0,0 +1,38 @@
+package terraform
+
+import (
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// extractOutputChanges lists root module output changes by name and
+// counts the outputs being removed. Values are never emitted, only
+// whether they are sensitive or unknown until apply.
+func extractOutputChanges(changes map[string]*tfjson.Change) ([]map[string]any, int) {
+	names := make([]string, 0, len(changes))
+	for name, c := range changes {
+		if c != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := make([]map[string]any, 0, len(names))
+	destroys := 0
+	for _, name := range names {
+		c := changes[name]
+		action := primaryAction(c.Actions)
+		if action == "delete" {
+			destroys++
+		}
+		out = append(out, map[string]any{
+			"name":             name,
+			"action":           action,
+			"before_sensitive": containsTrue(c.BeforeSensitive),
+			"after_sensitive":  containsTrue(c.AfterSensitive),
+			"after_unknown":    containsTrue(c.AfterUnknown),
+		})
+	}
+	return out, destroys
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -32,6 +33,11 @@ const (
 	defaultTruncateStrategy   = "drop_tail"
 )
 
+// rootModuleKey is the modules key for resources of the root module,
+// whose module address is empty. Module addresses always start with
+// "module.", so it cannot collide.
+const rootModuleKey = "root"
+
 // PlanAdapter converts `terraform show -json` output into Evidra skill input.
 type PlanAdapter struct{}
 
@@ -49,211 +55,577 @@ func (a *PlanAdapter) Convert(
 	if err := plan.Validate(); err != nil {
 		return nil, fmt.Errorf("terraform-plan: validate: %w", err)
 	}
+	opts, err := parseOptions(config)
+	if err != nil {
+		return nil, fmt.Errorf("terraform-plan: %w", err)
+	}
+
+	// --- Redact sensitive values before anything reads them ---
+	// Without an explicit salt, hashes are only comparable within this plan.
+	artifactSHA := sha256Hex(raw)
+	redact := newRedactor(opts.redactPatterns, configOrDefault(opts.redactionSalt, artifactSHA))
+	redact.redactPlan(&plan)
+
+	extras := parseExtras(raw)
+	scan := scanResourceChanges(&plan, extras, opts)
+	cfgIndex := newConfigIndex(plan.Config)
+
+	out := newOutput(opts.maxChanges)
+	out.addPlanStatus(&plan, extras)
+	out.addResourceChanges(scan, opts)
+	out.addCounts(&plan, scan)
+	out.addAddresses(scan)
+	out.addDrift(&plan, opts)
+	out.addDeferred(&plan, extras, opts)
+	out.addChecks(&plan)
+	out.addChangeAnalysis(&plan, scan.scoped, cfgIndex, opts)
+	out.addInputs(&plan, redact, opts)
+	out.addOutputChanges(&plan)
+	out.addDeepExtraction(&plan, scan, cfgIndex, opts)
+
+	if len(plan.ResourceChanges) > 500 {
+		out.warn("large plan with %d resources; consider EVIDRA_FILTER_RESOURCE_TYPES",
+			len(plan.ResourceChanges))
+	}
+
+	return &adapter.Result{
+		Input: out.input,
+		Metadata: map[string]any{
+			"adapter_name":          "terraform-plan",
+			"adapter_version":       Version,
+			"output_schema_version": OutputSchemaVersion,
+			"terraform_version":     plan.TerraformVersion,
+			"format_version":        plan.FormatVersion,
+			"resource_count":        len(plan.ResourceChanges),
+			"timestamp":             Now().UTC().Format(time.RFC3339),
+			"artifact_sha256":       artifactSHA,
+			"redacted_values":       redact.count,
+			"warnings":              out.warnings,
+		},
+	}, nil
+}
+
+// options is the parsed adapter configuration. Unknown keys are ignored.
+type options struct {
+	includeData       bool
+	filterTypes       map[string]bool
+	filterActions     map[string]bool
+	trustedAccounts   map[string]bool
+	requiredTags      []string
+	protectedPatterns []string
+	redactPatterns    []string
+	redactionSalt     string
+	maxChanges        int
+	sortOrder         string
+	truncateStrategy  string
+	weights           riskWeights
+	prices            priceTable
+}
+
+func parseOptions(config map[string]string) (options, error) {
+	opts := options{
+		includeData:       config["include_data_sources"] == "true",
+		filterTypes:       parseCSV(config["filter_resource_types"]),
+		filterActions:     parseCSV(config["filter_actions"]),
+		trustedAccounts:   parseCSV(config["trusted_account_ids"]),
+		requiredTags:      sortedKeys(parseCSV(config["required_tags"])),
+		protectedPatterns: sortedKeys(parseCSV(config["protected_resources"])),
+		redactPatterns:    sortedKeys(parseCSV(config["redact_attributes"])),
+		redactionSalt:     config["redaction_salt"],
+		maxChanges:        parseIntOrDefault(config["max_resource_changes"], defaultMaxResourceChanges),
+		sortOrder:         configOrDefault(config["resource_changes_sort"], defaultSort),
+		truncateStrategy:  configOrDefault(config["truncate_strategy"], defaultTruncateStrategy),
+	}
+	var err error
+	if opts.weights, err = parseRiskWeights(config["risk_weights"]); err != nil {
+		return opts, fmt.Errorf("risk_weights: %w", err)
+	}
+	if opts.prices, err = parsePriceTable(config["price_table"]); err != nil {
+		return opts, fmt.Errorf("price_table: %w", err)
+	}
+	return opts, nil
+}
+
+// typeInScope applies filter_resource_types.
+func (opts options) typeInScope(typ string) bool {
+	return len(opts.filterTypes) == 0 || opts.filterTypes[typ]
+}
+
+// inScope applies the scope filters: filter_resource_types and
+// include_data_sources.
+func (opts options) inScope(rc *tfjson.ResourceChange) bool {
+	return opts.typeInScope(rc.Type) && (rc.Mode != tfjson.DataResourceMode || opts.includeData)
+}
+
+// changeScan is what one pass over resource_changes collects.
+type changeScan struct {
+	creates, updates, deletes, replaces int
+	moves, imports, forgets             int
+	resourceTypes, providers            map[string]bool
+	deleteTypes, replaceTypes           map[string]bool
+
+	deleteAddresses, replaceAddresses               []string
+	moveAddresses, importAddresses, forgetAddresses []string
+	identityUnknownAddresses                        []string
+	createBeforeDestroy, destroyBeforeCreate        []string
+	unknownAttrs, attrCount                         int
+	modules, modulesRecursive                       map[string]map[string]int
+	actionReasons                                   map[string]int
+
+	// changes are the resource_changes entries, after filter_actions.
+	changes []map[string]any
+	// scoped are the in-scope changes every extraction works from.
+	scoped []*tfjson.ResourceChange
+	// policyDocs are out-of-scope aws_iam_policy_document reads.
+	policyDocs []*tfjson.ResourceChange
+}
 
-	// --- Parse config ---
-	includeData := config["include_data_sources"] == "true"
-	filterTypes := parseCSV(config["filter_resource_types"])
-	filterActions := parseCSV(config["filter_actions"])
-	maxChanges := parseIntOrDefault(config["max_resource_changes"], defaultMaxResourceChanges)
-	sortOrder := configOrDefault(config["resource_changes_sort"], defaultSort)
-	truncateStrategy := configOrDefault(config["truncate_strategy"], defaultTruncateStrategy)
-
-	// --- Single pass with two concerns ---
-	//
-	// SEMANTIC CONTRACT:
-	//   filter_resource_types → filters EVERYTHING (counts, types, changes).
-	//     Rationale: you asked "only look at hcloud_server" — counts should reflect that scope.
-	//   filter_actions → filters ONLY the resource_changes array.
-	//     Rationale: counts must reflect the full picture so policy can say
-	//     "there are 5 deletes" even when resource_changes only shows creates.
-	//   include_data_sources → filters EVERYTHING (data sources are not infra changes).
-	//
-	// This distinction matters: filter_resource_types narrows the *scope* of analysis,
-	// while filter_actions narrows the *detail* you want to see. Counts always
-	// reflect the full scope.
-
-	var creates, updates, deletes, replaces int
-	resourceTypes := map[string]bool{}
-	providers := map[string]bool{}
-	deleteTypes := map[string]bool{}
-	replaceTypes := map[string]bool{}
-	var deleteAddresses, replaceAddresses []string
-	var changes []map[string]any
-
-	for _, rc := range plan.ResourceChanges {
+// scanResourceChanges makes a single pass with two concerns.
+//
+// SEMANTIC CONTRACT:
+//
+//	filter_resource_types → filters EVERYTHING (counts, types, changes).
+//	  Rationale: you asked "only look at hcloud_server" — counts should reflect that scope.
+//	filter_actions → filters ONLY the resource_changes array.
+//	  Rationale: counts must reflect the full picture so policy can say
+//	  "there are 5 deletes" even when resource_changes only shows creates.
+//	include_data_sources → filters EVERYTHING (data sources are not infra changes).
+//
+// This distinction matters: filter_resource_types narrows the *scope* of analysis,
+// while filter_actions narrows the *detail* you want to see. Counts always
+// reflect the full scope.
+func scanResourceChanges(plan *tfjson.Plan, extras planExtras, opts options) *changeScan {
+	s := &changeScan{
+		resourceTypes:            map[string]bool{},
+		providers:                map[string]bool{},
+		deleteTypes:              map[string]bool{},
+		replaceTypes:             map[string]bool{},
+		moveAddresses:            []string{},
+		importAddresses:          []string{},
+		forgetAddresses:          []string{},
+		identityUnknownAddresses: []string{},
+		createBeforeDestroy:      []string{},
+		destroyBeforeCreate:      []string{},
+		modules:                  map[string]map[string]int{},
+		modulesRecursive:         map[string]map[string]int{},
+		actionReasons:            map[string]int{},
+	}
+
+	for i, rc := range plan.ResourceChanges {
 		if rc.Change == nil {
 			continue
 		}
 		// Scope filters: exclude from everything.
-		if rc.Mode == tfjson.DataResourceMode && !includeData {
-			continue
-		}
-		if len(filterTypes) > 0 && !filterTypes[rc.Type] {
+		if !opts.inScope(rc) {
+			// Policy documents are not infra changes, but when they are
+			// read during apply they are the only place the statements
+			// of the policies built from them are visible.
+			if opts.typeInScope(rc.Type) && rc.Type == "aws_iam_policy_document" {
+				s.policyDocs = append(s.policyDocs, rc)
+			}
 			continue
 		}
 
-		resourceTypes[rc.Type] = true
+		s.scoped = append(s.scoped, rc)
+		s.resourceTypes[rc.Type] = true
 		if rc.ProviderName != "" {
-			providers[rc.ProviderName] = true
+			s.providers[rc.ProviderName] = true
 		}
 
 		action := primaryAction(rc.Change.Actions)
+		reason := extras.actionReason(i)
+		if reason != "" {
+			s.actionReasons[reason]++
+		}
 
 		// --- Always count (regardless of filter_actions) ---
 		switch action {
 		case "create":
-			creates++
+			s.creates++
 		case "update":
-			updates++
+			s.updates++
 		case "delete":
-			deletes++
-			deleteTypes[rc.Type] = true
-			deleteAddresses = append(deleteAddresses, rc.Address)
+			s.deletes++
+			s.deleteTypes[rc.Type] = true
+			s.deleteAddresses = append(s.deleteAddresses, rc.Address)
 		case "replace":
-			replaces++
-			replaceTypes[rc.Type] = true
-			replaceAddresses = append(replaceAddresses, rc.Address)
+			s.replaces++
+			s.replaceTypes[rc.Type] = true
+			s.replaceAddresses = append(s.replaceAddresses, rc.Address)
+			// The action order is what Terraform will do, including
+			// create_before_destroy inherited from dependents.
+			if rc.Change.Actions.CreateBeforeDestroy() {
+				s.createBeforeDestroy = append(s.createBeforeDestroy, rc.Address)
+			} else {
+				s.destroyBeforeCreate = append(s.destroyBeforeCreate, rc.Address)
+			}
+		case "forget":
+			s.forgets++
+			s.forgetAddresses = append(s.forgetAddresses, rc.Address)
+		}
+
+		// Per-module counts, keyed by the module instance path, and
+		// cumulative counts keyed by static path and every ancestor.
+		module := rc.ModuleAddress
+		if module == "" {
+			module = rootModuleKey
+		}
+		addModuleCount(s.modules, module, action)
+		for _, path := range moduleAncestors(rc.ModuleAddress) {
+			addModuleCount(s.modulesRecursive, path, action)
+		}
+
+		// moved and import blocks are orthogonal to the action: a pure
+		// refactor is a no-op move, an import may also update.
+		moved := rc.PreviousAddress != "" && rc.PreviousAddress != rc.Address
+		if moved {
+			s.moves++
+			s.moveAddresses = append(s.moveAddresses, rc.Address)
+		}
+		importing := rc.Change.Importing != nil
+		if importing {
+			s.imports++
+			s.importAddresses = append(s.importAddresses, rc.Address)
+		}
+
+		// Values known only after apply. identity_unknown is meaningful
+		// on update only: create and replace always get a new identity.
+		var unknown []string
+		switch action {
+		case "create", "update", "replace", "read":
+			unknown = unknownPaths(rc.Change.AfterUnknown)
+			s.unknownAttrs += len(unknown)
+			s.attrCount += len(unknown) + knownLeaves(rc.Change.After)
+		}
+		idUnknown := action == "update" && identityUnknown(rc.Change)
+		if idUnknown {
+			s.identityUnknownAddresses = append(s.identityUnknownAddresses, rc.Address)
 		}
 
 		// --- Detail filter: only affects resource_changes array ---
-		if len(filterActions) > 0 && !filterActions[action] {
+		if len(opts.filterActions) > 0 && !opts.filterActions[action] {
 			continue
 		}
 
-		changes = append(changes, map[string]any{
-			"address":  rc.Address,
-			"type":     rc.Type,
-			"action":   action,
-			"provider": rc.ProviderName,
-		})
+		entry := map[string]any{
+			"address":        rc.Address,
+			"type":           rc.Type,
+			"action":         action,
+			"provider":       rc.ProviderName,
+			"module_address": rc.ModuleAddress,
+		}
+		if rc.Index != nil {
+			entry["index"] = rc.Index
+		}
+		if action == "update" || action == "replace" {
+			entry["changed_attributes"] = changedAttributes(rc.Change)
+		}
+		if unknown != nil {
+			entry["unknown_attributes"] = unknown
+			entry["unknown_count"] = len(unknown)
+		}
+		if action == "update" {
+			entry["identity_unknown"] = idUnknown
+		}
+		if reason != "" {
+			entry["action_reason"] = reason
+		}
+		if moved {
+			entry["previous_address"] = rc.PreviousAddress
+		}
+		if importing {
+			entry["importing"] = true
+		}
+		if action == "replace" {
+			entry["replace_order"] = "destroy_before_create"
+			if rc.Change.Actions.CreateBeforeDestroy() {
+				entry["replace_order"] = "create_before_destroy"
+			}
+		}
+		if action == "replace" && len(rc.Change.ReplacePaths) > 0 {
+			paths := make([]string, 0, len(rc.Change.ReplacePaths))
+			for _, p := range rc.Change.ReplacePaths {
+				if steps, ok := p.([]any); ok {
+					paths = append(paths, formatPath(steps))
+				}
+			}
+			entry["replace_paths"] = paths
+		}
+		s.changes = append(s.changes, entry)
 	}
 
 	// --- Sort (deterministic output) ---
-	if sortOrder == "address" {
-		sort.Slice(changes, func(i, j int) bool {
-			return changes[i]["address"].(string) < changes[j]["address"].(string)
+	if opts.sortOrder == "address" {
+		sort.Slice(s.changes, func(i, j int) bool {
+			return s.changes[i]["address"].(string) < s.changes[j]["address"].(string)
 		})
-		sort.Strings(deleteAddresses)
-		sort.Strings(replaceAddresses)
-	}
-
-	// --- Truncate ---
-	// Each array tracks its own total and truncated flag independently.
-	// resource_changes is subject to filter_actions, so its total may differ
-	// from total_changes. Address arrays are never filtered by filter_actions
-	// (they come from counts), so they have their own totals.
-
-	rcTotal := len(changes)
-	rcTruncated := false
-	if maxChanges >= 0 && rcTotal > maxChanges {
-		rcTruncated = true
-		if truncateStrategy == "summary_only" {
-			changes = nil
-		} else {
-			changes = changes[:maxChanges]
+		for _, addrs := range [][]string{
+			s.deleteAddresses, s.replaceAddresses,
+			s.moveAddresses, s.importAddresses, s.forgetAddresses,
+			s.identityUnknownAddresses, s.createBeforeDestroy, s.destroyBeforeCreate,
+		} {
+			sort.Strings(addrs)
 		}
 	}
+	return s
+}
 
-	deleteAddrTotal := len(deleteAddresses)
-	deleteAddrTruncated := false
-	if maxChanges >= 0 && deleteAddrTotal > maxChanges {
-		deleteAddrTruncated = true
-		deleteAddresses = deleteAddresses[:maxChanges]
+// addPlanStatus sets whether the plan errored, its mode and whether it
+// can be applied.
+func (o *output) addPlanStatus(plan *tfjson.Plan, extras planExtras) {
+	if extras.Errored {
+		o.warn("plan errored: Terraform stopped planning partway, counts may be incomplete")
+	}
+	if len(plan.ResourceChanges) == 0 {
+		o.warn("plan contains no resource changes")
+	}
+	if plan.TerraformVersion == "" {
+		o.warn("terraform_version missing from plan JSON")
 	}
+	mode := planMode(plan)
+	o.set(map[string]any{
+		// Set when planning failed partway: everything below is partial.
+		"plan_errored": extras.Errored,
+
+		// Plan mode (inferred) and applyability
+		"plan_mode": mode,
+		"applyable": planApplyable(plan, extras, mode),
+		"complete":  planComplete(plan, extras),
+	})
+}
 
-	replaceAddrTotal := len(replaceAddresses)
-	replaceAddrTruncated := false
-	if maxChanges >= 0 && replaceAddrTotal > maxChanges {
-		replaceAddrTruncated = true
-		replaceAddresses = replaceAddresses[:maxChanges]
+// addCounts sets the counts, classification and per-module breakdown.
+func (o *output) addCounts(plan *tfjson.Plan, s *changeScan) {
+	unknownRatio := 0.0
+	if s.attrCount > 0 {
+		unknownRatio = float64(s.unknownAttrs) / float64(s.attrCount)
 	}
+	o.set(map[string]any{
+		// Counts (always accurate within resource type scope)
+		"create_count":  s.creates,
+		"update_count":  s.updates,
+		"destroy_count": s.deletes,
+		"replace_count": s.replaces,
+		"total_changes": s.creates + s.updates + s.deletes + s.replaces,
+
+		// State-only operations (moved/import/removed blocks). Not part
+		// of total_changes: they do not touch infrastructure by themselves.
+		"move_count":   s.moves,
+		"import_count": s.imports,
+		"forget_count": s.forgets,
+
+		// Classification
+		"resource_types":  sortedKeys(s.resourceTypes),
+		"providers":       sortedKeys(s.providers),
+		"has_destroys":    s.deletes > 0,
+		"has_replaces":    s.replaces > 0,
+		"is_destroy_plan": s.deletes > 0 && s.creates == 0 && s.updates == 0 && s.replaces == 0,
+		"delete_types":    sortedKeys(s.deleteTypes),
+		"replace_types":   sortedKeys(s.replaceTypes),
+
+		// NOTE: drift_count and deferred_count are NOT scope-filtered.
+		// They reflect the entire plan regardless of filter_resource_types
+		// or include_data_sources. This is intentional — drift in an
+		// unfiltered resource type is still policy-relevant signal.
+		"drift_count":    len(plan.ResourceDrift),
+		"deferred_count": len(plan.DeferredChanges),
+
+		// Values known only after apply (create/update/replace/read)
+		"unknown_attribute_count": s.unknownAttrs,
+		"attribute_count":         s.attrCount,
+		"unknown_ratio":           unknownRatio,
+
+		// Counts per module path ("root" for the root module)
+		"modules":           s.modules,
+		"modules_recursive": s.modulesRecursive,
+
+		// Counts by Terraform's action_reason (e.g. replace_because_tainted)
+		"action_reason_counts": s.actionReasons,
+	})
+}
 
-	// --- Warnings ---
-	var warnings []string
+// addAddresses sets the address arrays. They come from the counts, so
+// filter_actions does not apply and each has its own total.
+func (o *output) addAddresses(s *changeScan) {
+	addCapped(o, "delete_addresses", s.deleteAddresses)
+	addCapped(o, "replace_addresses", s.replaceAddresses)
+	addCapped(o, "move_addresses", s.moveAddresses)
+	addCapped(o, "import_addresses", s.importAddresses)
+	addCapped(o, "forget_addresses", s.forgetAddresses)
 
-	if len(plan.ResourceChanges) == 0 {
-		warnings = append(warnings, "plan contains no resource changes")
+	// Replace ordering: destroy-before-create leaves a gap
+	o.input["create_before_destroy_count"] = addCapped(o, "create_before_destroy_addresses", s.createBeforeDestroy)
+	o.input["destroy_before_create_count"] = addCapped(o, "destroy_before_create_addresses", s.destroyBeforeCreate)
+
+	o.input["has_identity_unknown"] = addCapped(o, "identity_unknown_addresses", s.identityUnknownAddresses) > 0
+}
+
+// addDrift sets the drift detail (scope-filtered, unlike drift_count).
+func (o *output) addDrift(plan *tfjson.Plan, opts options) {
+	var scoped []*tfjson.ResourceChange
+	for _, rc := range plan.ResourceDrift {
+		if rc.Change != nil && opts.inScope(rc) {
+			scoped = append(scoped, rc)
+		}
 	}
-	if plan.TerraformVersion == "" {
-		warnings = append(warnings, "terraform_version missing from plan JSON")
+	drift, driftTypes := extractResourceDrift(scoped)
+	addCapped(o, "resource_drift", drift)
+	o.input["drift_types"] = driftTypes
+}
+
+// addDeferred sets the deferred detail (scope-filtered, unlike
+// deferred_count).
+func (o *output) addDeferred(plan *tfjson.Plan, extras planExtras, opts options) {
+	var scoped []deferredChange
+	for i, dc := range plan.DeferredChanges {
+		rc := dc.ResourceChange
+		if rc == nil || rc.Change == nil || !opts.inScope(rc) {
+			continue
+		}
+		reason := dc.Reason
+		if reason == "" {
+			reason = extras.deferredReason(i)
+		}
+		scoped = append(scoped, deferredChange{rc: rc, reason: reason})
 	}
-	if rcTruncated {
-		warnings = append(warnings,
-			fmt.Sprintf("resource_changes truncated: showing %d of %d",
-				len(changes), rcTotal))
+	deferred, reasons := extractDeferredChanges(scoped)
+	addCapped(o, "deferred_changes", deferred)
+	o.input["deferred_reason_counts"] = reasons
+}
+
+// addChangeAnalysis sets what is derived from the in-scope changes as a
+// whole: where they land, what depends on them, and policy summaries.
+func (o *output) addChangeAnalysis(
+	plan *tfjson.Plan, scoped []*tfjson.ResourceChange, cfgIndex *configIndex, opts options,
+) {
+	// Where the changes land (changed resources only)
+	loc := extractLocations(scoped, plan.Config, cfgIndex)
+	o.set(map[string]any{
+		"regions":       sortedKeys(loc.regions),
+		"accounts":      sortedKeys(loc.accounts),
+		"projects":      sortedKeys(loc.projects),
+		"subscriptions": sortedKeys(loc.subscriptions),
+	})
+
+	// Downstream dependents of deletes/replaces (configuration level)
+	blastRadius, maxDepth, dependentsTruncated := extractBlastRadius(scoped, newDepGraph(cfgIndex), o.max)
+	addCapped(o, "blast_radius", blastRadius)
+	if dependentsTruncated {
+		o.warn("blast_radius dependents truncated: showing at most %d per entry", o.max)
 	}
-	if deleteAddrTruncated {
-		warnings = append(warnings,
-			fmt.Sprintf("delete_addresses truncated: showing %d of %d",
-				len(deleteAddresses), deleteAddrTotal))
+	o.input["blast_radius_max_depth"] = maxDepth
+
+	// Deletes/replaces of protected_resources
+	o.input["touches_protected"] = addCapped(o, "protected_changes",
+		extractProtectedChanges(scoped, opts.protectedPatterns)) > 0
+
+	// Weighted score of the changes in scope (risk_weights)
+	score, level, top := scoreRisk(scoped, opts.weights)
+	o.set(map[string]any{
+		"risk_score":            score,
+		"risk_level":            level,
+		"risk_top_contributors": top,
+	})
+
+	// Estimated monthly cost change (price_table)
+	costDelta, breakdown, unpriced := extractCostDelta(scoped, opts.prices)
+	o.input["monthly_cost_delta"] = costDelta
+	addCapped(o, "cost_breakdown", breakdown)
+	addCapped(o, "unpriced_resources", unpriced)
+
+	// Required tag keys on created/updated resources
+	tags := extractTagCompliance(scoped, opts.requiredTags, o.max)
+	if tags["non_compliant_truncated"].(bool) {
+		o.warn("tag_compliance.non_compliant truncated: showing %d of %d",
+			o.max, tags["non_compliant_count"])
 	}
-	if replaceAddrTruncated {
-		warnings = append(warnings,
-			fmt.Sprintf("replace_addresses truncated: showing %d of %d",
-				len(replaceAddresses), replaceAddrTotal))
+	o.set(map[string]any{
+		"tag_compliance":     tags,
+		"has_tag_violations": tags["non_compliant_count"].(int) > 0,
+	})
+}
+
+// addInputs sets the root variables and provider configurations (not
+// scope-filtered; variable values are never emitted).
+func (o *output) addInputs(plan *tfjson.Plan, redact *redactor, opts options) {
+	addCapped(o, "variables", extractVariables(plan, redact, opts.redactionSalt))
+	addCapped(o, "provider_configs", extractProviderConfigs(plan.Config))
+}
+
+// addChecks sets the check results (not scope-filtered: a failed check
+// blocks the whole apply).
+func (o *output) addChecks(plan *tfjson.Plan) {
+	checks := extractCheckResults(plan.Checks, o.max)
+	if checks["addresses_truncated"].(bool) {
+		o.warn("check_results addresses truncated: showing at most %d per status", o.max)
 	}
-	if len(plan.ResourceChanges) > 500 {
-		warnings = append(warnings,
-			fmt.Sprintf("large plan with %d resources; consider EVIDRA_FILTER_RESOURCE_TYPES",
-				len(plan.ResourceChanges)))
+	o.set(map[string]any{
+		"check_results":     checks,
+		"has_failed_checks": checks["fail_count"].(int) > 0,
+	})
+}
+
+// addOutputChanges sets the root module outputs (not scope-filtered:
+// outputs are not resources).
+func (o *output) addOutputChanges(plan *tfjson.Plan) {
+	outputChanges, destroys := extractOutputChanges(plan.OutputChanges)
+	addCapped(o, "output_changes", outputChanges)
+	o.input["output_destroy_count"] = destroys
+}
+
+// addDeepExtraction sets the normalized resource configuration from
+// change.after (scope-filtered, not affected by filter_actions).
+// Shortcuts are computed before truncation.
+func (o *output) addDeepExtraction(plan *tfjson.Plan, s *changeScan, cfgIndex *configIndex, opts options) {
+	addCapped(o, "security_group_rules", extractSecurityGroupRules(s.scoped))
+
+	iamStatements, iamWarnings := extractIAMPolicyStatements(append(s.scoped, s.policyDocs...))
+	addCapped(o, "iam_policy_statements", iamStatements)
+
+	trustStatements, trustWarnings := extractTrustPolicyStatements(
+		s.scoped, opts.trustedAccounts, plan.Config, cfgIndex)
+	hasCrossAccountTrust, hasWildcardTrust := false, false
+	for _, st := range trustStatements {
+		hasCrossAccountTrust = hasCrossAccountTrust || st["cross_account"].(bool)
+		hasWildcardTrust = hasWildcardTrust || st["wildcard_principal"].(bool)
 	}
-	if warnings == nil {
-		warnings = []string{}
+	addCapped(o, "trust_policy_statements", trustStatements)
+	o.input["has_cross_account_trust"] = hasCrossAccountTrust
+	o.input["has_wildcard_trust"] = hasWildcardTrust
+
+	s3PAB, s3SSE, s3Unencrypted := extractS3Config(s.scoped, cfgIndex)
+	addCapped(o, "s3_public_access_block", s3PAB)
+	addCapped(o, "server_side_encryption", s3SSE)
+	addCapped(o, "s3_buckets_without_encryption", s3Unencrypted)
+
+	exposure := extractNetworkExposure(s.scoped)
+	hasNewExposure := false
+	for _, e := range exposure {
+		hasNewExposure = hasNewExposure || e["new"].(bool)
 	}
+	addCapped(o, "network_exposure", exposure)
+	o.input["has_new_network_exposure"] = hasNewExposure
 
-	// --- Compose result ---
-	isDestroyPlan := deletes > 0 && creates == 0 && updates == 0 && replaces == 0
+	o.warnings = append(o.warnings, iamWarnings...)
+	o.warnings = append(o.warnings, trustWarnings...)
+}
 
-	return &adapter.Result{
-		Input: map[string]any{
-			// Counts (always accurate within resource type scope)
-			"create_count":  creates,
-			"update_count":  updates,
-			"destroy_count": deletes,
-			"replace_count": replaces,
-			"total_changes": creates + updates + deletes + replaces,
-
-			// Classification
-			"resource_types":  sortedKeys(resourceTypes),
-			"providers":       sortedKeys(providers),
-			"has_destroys":    deletes > 0,
-			"has_replaces":    replaces > 0,
-			"is_destroy_plan": isDestroyPlan,
-
-			// NOTE: drift_count and deferred_count are NOT scope-filtered.
-			// They reflect the entire plan regardless of filter_resource_types
-			// or include_data_sources. This is intentional — drift in an
-			// unfiltered resource type is still policy-relevant signal.
-			"drift_count":    len(plan.ResourceDrift),
-			"deferred_count": len(plan.DeferredChanges),
-
-			// Risk shortcuts (not affected by filter_actions)
-			"delete_types":                sortedKeys(deleteTypes),
-			"replace_types":               sortedKeys(replaceTypes),
-			"delete_addresses":            deleteAddresses,
-			"delete_addresses_total":      deleteAddrTotal,
-			"delete_addresses_truncated":  deleteAddrTruncated,
-			"replace_addresses":           replaceAddresses,
-			"replace_addresses_total":     replaceAddrTotal,
-			"replace_addresses_truncated": replaceAddrTruncated,
-
-			// Per-resource detail (subject to filter_actions + truncation)
-			"resource_changes":           changes,
-			"resource_changes_count":     rcTotal,
-			"resource_changes_truncated": rcTruncated,
-		},
-		Metadata: map[string]any{
-			"adapter_name":          "terraform-plan",
-			"adapter_version":       Version,
-			"output_schema_version": OutputSchemaVersion,
-			"terraform_version":     plan.TerraformVersion,
-			"format_version":        plan.FormatVersion,
-			"resource_count":        len(plan.ResourceChanges),
-			"timestamp":             Now().UTC().Format(time.RFC3339),
-			"artifact_sha256":       sha256Hex(raw),
-			"warnings":              warnings,
-		},
-	}, nil
+// addResourceChanges sets the per-resource detail, subject to
+// filter_actions and truncation. Its total is resource_changes_count:
+// it may differ from total_changes because of filter_actions.
+func (o *output) addResourceChanges(s *changeScan, opts options) {
+	changes, total, truncated := truncate(s.changes, o.max)
+	if truncated {
+		if opts.truncateStrategy == "summary_only" {
+			changes = nil
+		}
+		o.warn("resource_changes truncated: showing %d of %d", len(changes), total)
+	}
+	o.set(map[string]any{
+		"resource_changes":           changes,
+		"resource_changes_count":     total,
+		"resource_changes_truncated": truncated,
+	})
 }
 
 // primaryAction maps tfjson.Actions to a single action string.
@@ -274,6 +646,10 @@ func primaryAction(actions tfjson.Actions) string {
 	if actions.Read() {
 		return "read"
 	}
+	// Terraform 1.7+: removed block with destroy = false.
+	if actions.Forget() {
+		return "forget"
+	}
 	// Future-proofing: if Terraform adds new action types,
 	// return "unknown" rather than silently mapping to "noop".
 	if actions.NoOp() {
@@ -282,6 +658,71 @@ func primaryAction(actions tfjson.Actions) string {
 	return "unknown"
 }
 
+// addModuleCount counts one change under key, creating the entry with
+// all counts at zero on first use.
+func addModuleCount(modules map[string]map[string]int, key, action string) {
+	counts := modules[key]
+	if counts == nil {
+		counts = map[string]int{
+			"create_count": 0, "update_count": 0, "destroy_count": 0,
+			"replace_count": 0, "total_changes": 0,
+		}
+		modules[key] = counts
+	}
+	switch action {
+	case "create", "update", "replace":
+		counts[action+"_count"]++
+		counts["total_changes"]++
+	case "delete":
+		counts["destroy_count"]++
+		counts["total_changes"]++
+	}
+}
+
+// moduleAncestors returns the static paths a module instance lies in,
+// from the root down: module.a[0].module.b["x"] gives root, module.a,
+// module.a.module.b.
+func moduleAncestors(moduleAddress string) []string {
+	out := []string{rootModuleKey}
+	parts := strings.Split(stripIndexes(moduleAddress), ".")
+	for i := 1; i < len(parts); i += 2 {
+		out = append(out, strings.Join(parts[:i+1], "."))
+	}
+	return out
+}
+
+// changedAttributes lists the top-level attributes whose value differs
+// between before and after, plus those that stay unknown until apply.
+// Only attribute names are returned — values never leave the adapter.
+func changedAttributes(c *tfjson.Change) []string {
+	before, after := asMap(c.Before), asMap(c.After)
+	changed := map[string]bool{}
+	// A value that is sensitive as a whole is a single redaction marker:
+	// its keys are not attributes, and which attributes changed inside it
+	// cannot be told. Only after_unknown names attributes then.
+	if isRedacted(before) || isRedacted(after) {
+		before, after = nil, nil
+	}
+	for k, v := range after {
+		if !reflect.DeepEqual(before[k], v) {
+			changed[k] = true
+		}
+	}
+	// Attributes missing from after are unknown; they are listed in
+	// after_unknown, but older plans may omit it.
+	for k, v := range before {
+		if _, ok := after[k]; !ok && v != nil {
+			changed[k] = true
+		}
+	}
+	for k, v := range asMap(c.AfterUnknown) {
+		if containsTrue(v) {
+			changed[k] = true
+		}
+	}
+	return sortedKeys(changed)
+}
+
 func sha256Hex(data []byte) string {
 	h := sha256.Sum256(data)
 	return hex.EncodeToString(h[:])
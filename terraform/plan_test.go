@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -415,6 +417,61 @@ func TestPlanAdapter_WithDeferred(t *testing.T) {
 	if deferredCount != 1 {
 		t.Errorf("expected deferred_count=1, got %d", deferredCount)
 	}
+
+	deferred := result.Input["deferred_changes"].([]map[string]any)
+	if len(deferred) != 1 {
+		t.Fatalf("deferred_changes: expected 1 entry, got %d", len(deferred))
+	}
+	assertStr(t, "deferred[0].address", "hcloud_volume.deferred", deferred[0]["address"])
+	assertStr(t, "deferred[0].type", "hcloud_volume", deferred[0]["type"])
+	assertStr(t, "deferred[0].action", "create", deferred[0]["action"])
+	assertStr(t, "deferred[0].reason", "provider_config_unknown", deferred[0]["reason"])
+}
+
+func TestPlanAdapter_DeferredReasons(t *testing.T) {
+	t.Parallel()
+
+	// Terraform's own encoding has a top-level "reason" per entry.
+	raw := []byte(`{
+		"format_version": "1.2",
+		"terraform_version": "1.10.0",
+		"resource_changes": [],
+		"deferred_changes": [
+			{"reason": "resource_config_unknown", "resource_change": {
+				"address": "hcloud_server.web[\"a\"]", "mode": "managed", "type": "hcloud_server", "name": "web",
+				"change": {"actions": ["create"], "before": null, "after": {}, "after_unknown": {}}}},
+			{"reason": "resource_config_unknown", "resource_change": {
+				"address": "hcloud_server.web[\"b\"]", "mode": "managed", "type": "hcloud_server", "name": "web",
+				"change": {"actions": ["create"], "before": null, "after": {}, "after_unknown": {}}}},
+			{"reason": "absent_prereq", "resource_change": {
+				"address": "hcloud_volume.data", "mode": "managed", "type": "hcloud_volume", "name": "data",
+				"change": {"actions": ["update"], "before": {}, "after": {}, "after_unknown": {}}}},
+			{"reason": "provider_config_unknown", "resource_change": {
+				"address": "data.hcloud_image.ubuntu", "mode": "data", "type": "hcloud_image", "name": "ubuntu",
+				"change": {"actions": ["read"], "before": null, "after": {}, "after_unknown": {}}}}
+		]
+	}`)
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "hcloud_server,hcloud_volume,hcloud_image",
+		"max_resource_changes":  "2",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// deferred_count is not scope-filtered; the detail excludes the data source.
+	assertInt(t, "deferred_count", 4, result.Input["deferred_count"])
+	assertInt(t, "deferred_changes_total", 3, result.Input["deferred_changes_total"])
+	assertBool(t, "deferred_changes_truncated", true, result.Input["deferred_changes_truncated"])
+
+	want := map[string]int{"resource_config_unknown": 2, "absent_prereq": 1}
+	if got := result.Input["deferred_reason_counts"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("deferred_reason_counts: got %v, want %v", got, want)
+	}
+
+	deferred := result.Input["deferred_changes"].([]map[string]any)
+	assertStr(t, "deferred[0].address", `hcloud_server.web["a"]`, deferred[0]["address"])
+	assertStr(t, "deferred[0].reason", "resource_config_unknown", deferred[0]["reason"])
 }
 
 func TestPlanAdapter_DataSources_ExcludedByDefault(t *testing.T) {
@@ -687,6 +744,627 @@ func TestPlanAdapter_Warnings_Truncation(t *testing.T) {
 	}
 }
 
+func TestPlanAdapter_ChangedAttributes(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "mixed_changes.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+
+	if got := byAddress["hcloud_server.web"]["changed_attributes"]; !reflect.DeepEqual(got, []string{"server_type"}) {
+		t.Errorf("update changed_attributes: got %v", got)
+	}
+	if got := byAddress["hcloud_server.db"]["changed_attributes"]; !reflect.DeepEqual(got, []string{"image"}) {
+		t.Errorf("replace changed_attributes: got %v", got)
+	}
+	for _, addr := range []string{"hcloud_firewall.evidra", "hcloud_volume.data"} {
+		if _, ok := byAddress[addr]["changed_attributes"]; ok {
+			t.Errorf("%s: changed_attributes only applies to update/replace", addr)
+		}
+	}
+}
+
+func TestPlanAdapter_ChangedAttributes_Unknown(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{
+		"format_version": "1.2",
+		"terraform_version": "1.10.0",
+		"resource_changes": [{
+			"address": "hcloud_server.web",
+			"mode": "managed",
+			"type": "hcloud_server",
+			"name": "web",
+			"provider_name": "registry.terraform.io/hetznercloud/hcloud",
+			"change": {
+				"actions": ["update"],
+				"before": {"name": "web", "ipv4_address": "1.2.3.4", "labels": {"env": "prod"}, "password": "s3cret"},
+				"after": {"name": "web", "labels": {"env": "prod"}, "password": "s3cret"},
+				"after_unknown": {"ipv4_address": true, "labels": {}, "network": [{"ip": true}]}
+			}
+		}]
+	}`)
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	change := result.Input["resource_changes"].([]map[string]any)[0]
+	want := []string{"ipv4_address", "network"}
+	if got := change["changed_attributes"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed_attributes: got %v, want %v", got, want)
+	}
+}
+
+func TestPlanAdapter_ActionReasons(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "action_reasons.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := result.Input["action_reason_counts"].(map[string]int)
+	want := map[string]int{
+		"replace_because_tainted":           1,
+		"replace_because_cannot_update":     2,
+		"delete_because_no_resource_config": 1,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("action_reason_counts: got %v, want %v", counts, want)
+	}
+
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+
+	tainted := byAddress["hcloud_server.tainted"]
+	assertStr(t, "tainted.action_reason", "replace_because_tainted", tainted["action_reason"])
+	if _, ok := tainted["replace_paths"]; ok {
+		t.Error("tainted replace has no forcing attributes; replace_paths should be absent")
+	}
+
+	ami := byAddress["hcloud_server.ami"]
+	if got := ami["replace_paths"]; !reflect.DeepEqual(got, []string{"image"}) {
+		t.Errorf("ami.replace_paths: got %v", got)
+	}
+	volume := byAddress["hcloud_volume.data"]
+	wantPaths := []string{"size", `labels["kubernetes.io/role"]`, "network[0].ip"}
+	if got := volume["replace_paths"]; !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("volume.replace_paths: got %v, want %v", got, wantPaths)
+	}
+
+	assertStr(t, "old.action_reason", "delete_because_no_resource_config",
+		byAddress["hcloud_firewall.old"]["action_reason"])
+	if _, ok := byAddress["hcloud_server.web"]["action_reason"]; ok {
+		t.Error("plain update should have no action_reason")
+	}
+}
+
+func TestPlanAdapter_MovedImportForget(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "moved_import_forget.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertInt(t, "move_count", 1, result.Input["move_count"])
+	assertInt(t, "import_count", 2, result.Input["import_count"])
+	assertInt(t, "forget_count", 1, result.Input["forget_count"])
+
+	// Forget leaves infrastructure alone: not a delete, not in total_changes.
+	assertInt(t, "destroy_count", 0, result.Input["destroy_count"])
+	assertInt(t, "total_changes", 2, result.Input["total_changes"])
+
+	if got := result.Input["move_addresses"]; !reflect.DeepEqual(got, []string{"hcloud_server.web"}) {
+		t.Errorf("move_addresses: got %v", got)
+	}
+	wantImports := []string{"hcloud_firewall.edge", "hcloud_volume.data"}
+	if got := result.Input["import_addresses"]; !reflect.DeepEqual(got, wantImports) {
+		t.Errorf("import_addresses: got %v, want %v", got, wantImports)
+	}
+	if got := result.Input["forget_addresses"]; !reflect.DeepEqual(got, []string{"hcloud_network.legacy"}) {
+		t.Errorf("forget_addresses: got %v", got)
+	}
+	assertBool(t, "import_addresses_truncated", false, result.Input["import_addresses_truncated"])
+
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+
+	web := byAddress["hcloud_server.web"]
+	assertStr(t, "web.action", "noop", web["action"])
+	assertStr(t, "web.previous_address", "hcloud_server.app", web["previous_address"])
+
+	assertBool(t, "data.importing", true, byAddress["hcloud_volume.data"]["importing"])
+	edge := byAddress["hcloud_firewall.edge"]
+	assertStr(t, "edge.action", "update", edge["action"])
+	assertBool(t, "edge.importing", true, edge["importing"])
+
+	assertStr(t, "legacy.action", "forget", byAddress["hcloud_network.legacy"]["action"])
+
+	// Empty address arrays serialise as [] rather than null.
+	empty, err := (&terraform.PlanAdapter{}).Convert(context.Background(), loadFixture(t, "simple_create.json"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, field := range []string{"move_addresses", "import_addresses", "forget_addresses"} {
+		if got := empty.Input[field]; !reflect.DeepEqual(got, []string{}) {
+			t.Errorf("%s: expected empty slice, got %#v", field, got)
+		}
+	}
+
+	worker := byAddress["hcloud_server.worker"]
+	for _, field := range []string{"previous_address", "importing"} {
+		if _, ok := worker[field]; ok {
+			t.Errorf("worker: %s should be absent", field)
+		}
+	}
+}
+
+func TestPlanAdapter_Modules(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "nested_modules.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	modules := result.Input["modules"].(map[string]map[string]int)
+	want := map[string]map[string]int{
+		"root": {
+			"create_count": 0, "update_count": 1, "destroy_count": 0,
+			"replace_count": 0, "total_changes": 1,
+		},
+		"module.network.module.vpc": {
+			"create_count": 2, "update_count": 0, "destroy_count": 0,
+			"replace_count": 0, "total_changes": 2,
+		},
+		"module.database[0]": {
+			"create_count": 0, "update_count": 0, "destroy_count": 1,
+			"replace_count": 1, "total_changes": 2,
+		},
+	}
+	if !reflect.DeepEqual(modules, want) {
+		t.Errorf("modules: got %v, want %v", modules, want)
+	}
+
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+
+	bastion := byAddress["hcloud_server.bastion"]
+	assertStr(t, "bastion.module_address", "", bastion["module_address"])
+	if _, ok := bastion["index"]; ok {
+		t.Error("bastion: index should be absent for a single-instance resource")
+	}
+
+	db := byAddress["module.database[0].hcloud_server.db[0]"]
+	assertStr(t, "db.module_address", "module.database[0]", db["module_address"])
+	if got, ok := db["index"].(float64); !ok || got != 0 {
+		t.Errorf("db.index: got %v (%T), want 0", db["index"], db["index"])
+	}
+
+	subnet := byAddress[`module.network.module.vpc.hcloud_network_subnet.private["eu-central"]`]
+	assertStr(t, "subnet.index", "eu-central", subnet["index"])
+}
+
+func TestPlanAdapter_Modules_ScopeFilter(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "nested_modules.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "hcloud_volume",
+		"filter_actions":        "create",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Scope narrows modules; filter_actions does not.
+	modules := result.Input["modules"].(map[string]map[string]int)
+	if len(modules) != 1 {
+		t.Fatalf("modules: expected only module.database[0], got %v", modules)
+	}
+	assertInt(t, "database.replace_count", 1, modules["module.database[0]"]["replace_count"])
+}
+
+func TestPlanAdapter_ModulesRecursive(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "module_siblings.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Instances of module.database and its nested module.backup all count
+	// under module.database; the module.database_replica sibling does not.
+	recursive := result.Input["modules_recursive"].(map[string]map[string]int)
+	want := map[string]map[string]int{
+		"root": {
+			"create_count": 2, "update_count": 1, "destroy_count": 2,
+			"replace_count": 0, "total_changes": 5,
+		},
+		"module.database": {
+			"create_count": 0, "update_count": 1, "destroy_count": 2,
+			"replace_count": 0, "total_changes": 3,
+		},
+		"module.database.module.backup": {
+			"create_count": 0, "update_count": 0, "destroy_count": 1,
+			"replace_count": 0, "total_changes": 1,
+		},
+		"module.database_replica": {
+			"create_count": 1, "update_count": 0, "destroy_count": 0,
+			"replace_count": 0, "total_changes": 1,
+		},
+	}
+	if !reflect.DeepEqual(recursive, want) {
+		t.Errorf("modules_recursive:\n got %v\nwant %v", recursive, want)
+	}
+
+	// modules keeps instance paths and direct resources only.
+	modules := result.Input["modules"].(map[string]map[string]int)
+	if _, ok := modules["module.database"]; ok {
+		t.Error("modules: module.database should only appear with its instance keys")
+	}
+	assertInt(t, "modules[module.database[0]].destroy_count", 1, modules["module.database[0]"]["destroy_count"])
+}
+
+func TestPlanAdapter_OutputChanges(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "output_changes.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		// Outputs are not resources: the type filter does not apply.
+		"filter_resource_types": "hcloud_network",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertInt(t, "output_destroy_count", 1, result.Input["output_destroy_count"])
+	assertInt(t, "output_changes_total", 4, result.Input["output_changes_total"])
+
+	outputs := result.Input["output_changes"].([]map[string]any)
+	var names []string
+	byName := map[string]map[string]any{}
+	for _, o := range outputs {
+		names = append(names, o["name"].(string))
+		byName[o["name"].(string)] = o
+	}
+	wantNames := []string{"db_endpoint", "db_password", "network_id", "web_ip"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("output names: got %v, want %v", names, wantNames)
+	}
+
+	assertStr(t, "db_endpoint.action", "delete", byName["db_endpoint"]["action"])
+	assertStr(t, "network_id.action", "noop", byName["network_id"]["action"])
+
+	password := byName["db_password"]
+	assertBool(t, "db_password.before_sensitive", true, password["before_sensitive"])
+	assertBool(t, "db_password.after_sensitive", true, password["after_sensitive"])
+	if _, ok := password["after"]; ok {
+		t.Error("output values must not be emitted")
+	}
+
+	webIP := byName["web_ip"]
+	assertStr(t, "web_ip.action", "create", webIP["action"])
+	assertBool(t, "web_ip.after_unknown", true, webIP["after_unknown"])
+	assertBool(t, "web_ip.after_sensitive", false, webIP["after_sensitive"])
+}
+
+func TestPlanAdapter_OutputChanges_Empty(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "simple_create.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(result.Input["output_changes"].([]map[string]any)); n != 0 {
+		t.Errorf("output_changes: expected empty, got %d", n)
+	}
+	assertInt(t, "output_destroy_count", 0, result.Input["output_destroy_count"])
+}
+
+func TestPlanAdapter_ResourceDrift(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "drift_detail.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertInt(t, "resource_drift_total", 3, result.Input["resource_drift_total"])
+	wantTypes := []string{"aws_db_instance", "aws_iam_role", "hcloud_server"}
+	if got := result.Input["drift_types"]; !reflect.DeepEqual(got, wantTypes) {
+		t.Errorf("drift_types: got %v, want %v", got, wantTypes)
+	}
+
+	drift := result.Input["resource_drift"].([]map[string]any)
+	byAddress := map[string]map[string]any{}
+	for _, d := range drift {
+		byAddress[d["address"].(string)] = d
+	}
+
+	role := byAddress["aws_iam_role.deploy"]
+	assertStr(t, "role.action", "update", role["action"])
+	wantPaths := []string{"managed_policy_arns", "max_session_duration", "tags.Owner"}
+	if got := role["changed_paths"]; !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("role.changed_paths: got %v, want %v", got, wantPaths)
+	}
+
+	// Redacted values are compared by hash and reported as one path.
+	db := byAddress["aws_db_instance.main"]
+	if got := db["changed_paths"]; !reflect.DeepEqual(got, []string{"password"}) {
+		t.Errorf("db.changed_paths: got %v", got)
+	}
+
+	gone := byAddress["hcloud_server.gone"]
+	assertStr(t, "gone.action", "delete", gone["action"])
+	// Deleted out-of-band: the whole object is gone, no per-path detail.
+	if got := gone["changed_paths"]; !reflect.DeepEqual(got, []string{}) {
+		t.Errorf("gone.changed_paths: got %v", got)
+	}
+}
+
+func TestPlanAdapter_ResourceDrift_ScopeAndTruncation(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "drift_detail.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "aws_iam_role,aws_db_instance",
+		"max_resource_changes":  "1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// drift_count stays unscoped; the detail follows the scope filter.
+	assertInt(t, "drift_count", 3, result.Input["drift_count"])
+	assertInt(t, "resource_drift_total", 2, result.Input["resource_drift_total"])
+	assertBool(t, "resource_drift_truncated", true, result.Input["resource_drift_truncated"])
+
+	drift := result.Input["resource_drift"].([]map[string]any)
+	if len(drift) != 1 {
+		t.Fatalf("resource_drift: expected 1 entry, got %d", len(drift))
+	}
+	assertStr(t, "resource_drift[0].address", "aws_db_instance.main", drift[0]["address"])
+
+	warnings := result.Metadata["warnings"].([]string)
+	found := false
+	for _, w := range warnings {
+		if w == "resource_drift truncated: showing 1 of 2" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected drift truncation warning, got %v", warnings)
+	}
+}
+
+func TestPlanAdapter_CheckResults(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "with_checks.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := result.Input["check_results"].(map[string]any)
+	assertInt(t, "pass_count", 1, checks["pass_count"])
+	assertInt(t, "fail_count", 2, checks["fail_count"])
+	assertInt(t, "error_count", 1, checks["error_count"])
+	assertInt(t, "unknown_count", 1, checks["unknown_count"])
+
+	wantFailed := []string{"check.health", "hcloud_server.web[1]"}
+	if got := checks["failed_addresses"]; !reflect.DeepEqual(got, wantFailed) {
+		t.Errorf("failed_addresses: got %v, want %v", got, wantFailed)
+	}
+	if got := checks["errored_addresses"]; !reflect.DeepEqual(got, []string{"var.region"}) {
+		t.Errorf("errored_addresses: got %v", got)
+	}
+	assertBool(t, "addresses_truncated", false, checks["addresses_truncated"])
+	assertBool(t, "has_failed_checks", true, result.Input["has_failed_checks"])
+}
+
+func TestPlanAdapter_CheckResults_None(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "simple_create.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := result.Input["check_results"].(map[string]any)
+	assertInt(t, "fail_count", 0, checks["fail_count"])
+	if got := checks["failed_addresses"]; !reflect.DeepEqual(got, []string{}) {
+		t.Errorf("failed_addresses: expected empty, got %v", got)
+	}
+	assertBool(t, "has_failed_checks", false, result.Input["has_failed_checks"])
+}
+
+func TestPlanAdapter_UnknownValues(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "unknown_values.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+
+	web := byAddress["hcloud_server.web"]
+	wantPaths := []string{"id", "ipv4_address", "network[0].ip"}
+	if got := web["unknown_attributes"]; !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("web.unknown_attributes: got %v, want %v", got, wantPaths)
+	}
+	assertInt(t, "web.unknown_count", 3, web["unknown_count"])
+	if _, ok := web["identity_unknown"]; ok {
+		t.Error("identity_unknown is only reported on updates")
+	}
+
+	app := byAddress["aws_instance.app"]
+	assertBool(t, "app.identity_unknown", true, app["identity_unknown"])
+	if got := app["unknown_attributes"]; !reflect.DeepEqual(got, []string{"arn"}) {
+		t.Errorf("app.unknown_attributes: got %v", got)
+	}
+
+	logs := byAddress["aws_s3_bucket.logs"]
+	assertBool(t, "logs.identity_unknown", false, logs["identity_unknown"])
+	assertInt(t, "logs.unknown_count", 0, logs["unknown_count"])
+
+	if _, ok := byAddress["hcloud_volume.old"]["unknown_attributes"]; ok {
+		t.Error("delete has no planned values; unknown_attributes should be absent")
+	}
+
+	// web: 4 known leaves + 3 unknown, app: 2 + 1, logs: 2 + 0.
+	assertInt(t, "unknown_attribute_count", 4, result.Input["unknown_attribute_count"])
+	assertInt(t, "attribute_count", 12, result.Input["attribute_count"])
+	if got := result.Input["unknown_ratio"].(float64); got < 0.333 || got > 0.334 {
+		t.Errorf("unknown_ratio: got %v, want 1/3", got)
+	}
+
+	assertBool(t, "has_identity_unknown", true, result.Input["has_identity_unknown"])
+	if got := result.Input["identity_unknown_addresses"]; !reflect.DeepEqual(got, []string{"aws_instance.app"}) {
+		t.Errorf("identity_unknown_addresses: got %v", got)
+	}
+}
+
+func TestPlanAdapter_PlanErrored(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "errored_plan.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("errored plans still convert, got error: %v", err)
+	}
+	assertBool(t, "plan_errored", true, result.Input["plan_errored"])
+	assertInt(t, "create_count", 1, result.Input["create_count"])
+
+	warnings := result.Metadata["warnings"].([]string)
+	found := false
+	for _, w := range warnings {
+		if containsStr(w, "plan errored") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected plan errored warning, got %v", warnings)
+	}
+
+	normal, err := (&terraform.PlanAdapter{}).Convert(context.Background(), loadFixture(t, "simple_create.json"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertBool(t, "plan_errored", false, normal.Input["plan_errored"])
+}
+
+func TestPlanAdapter_PlanMode(t *testing.T) {
+	t.Parallel()
+
+	priorState := `"prior_state": {"format_version": "1.0", "terraform_version": "1.10.0", "values": {"root_module": {
+		"resources": [{"address": "hcloud_server.web", "mode": "managed", "type": "hcloud_server", "name": "web"}]}}}`
+	drift := `"resource_drift": [{"address": "hcloud_server.web", "mode": "managed", "type": "hcloud_server", "name": "web",
+		"change": {"actions": ["update"], "before": {"name": "web"}, "after": {"name": "web2"}, "after_unknown": {}}}]`
+	plan := func(fields ...string) []byte {
+		return []byte(`{"format_version": "1.2", "terraform_version": "1.10.0"` + "," +
+			strings.Join(append([]string{`"variables": {}`}, fields...), ",") + "}")
+	}
+
+	tests := []struct {
+		name      string
+		raw       []byte
+		mode      string
+		applyable bool
+		complete  bool
+	}{
+		{"normal", loadFixture(t, "simple_create.json"), "normal", true, true},
+		{"destroy", loadFixture(t, "destroy_only.json"), "destroy", true, true},
+		{"deferred is incomplete", loadFixture(t, "with_deferred.json"), "normal", true, false},
+		{"errored", loadFixture(t, "errored_plan.json"), "normal", false, false},
+		{"refresh-only with drift", plan(priorState, drift), "refresh-only", true, true},
+		{"refresh-only without drift", plan(priorState), "refresh-only", false, true},
+		{"empty configuration", plan(), "normal", false, true},
+		{"recorded flags win", plan(`"resource_changes": []`, `"applyable": true`, `"complete": false`), "normal", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), tt.raw, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertStr(t, "plan_mode", tt.mode, result.Input["plan_mode"])
+			assertBool(t, "applyable", tt.applyable, result.Input["applyable"])
+			assertBool(t, "complete", tt.complete, result.Input["complete"])
+		})
+	}
+}
+
+func TestPlanAdapter_ReplaceOrder(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{
+		"format_version": "1.2",
+		"terraform_version": "1.10.0",
+		"resource_changes": [
+			{"address": "aws_db_instance.main", "mode": "managed", "type": "aws_db_instance", "name": "main",
+			 "change": {"actions": ["delete", "create"], "before": {}, "after": {}, "after_unknown": {}}},
+			{"address": "aws_launch_template.web", "mode": "managed", "type": "aws_launch_template", "name": "web",
+			 "change": {"actions": ["create", "delete"], "before": {}, "after": {}, "after_unknown": {}}},
+			{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web",
+			 "change": {"actions": ["update"], "before": {}, "after": {}, "after_unknown": {}}}
+		]
+	}`)
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertInt(t, "replace_count", 2, result.Input["replace_count"])
+	assertInt(t, "create_before_destroy_count", 1, result.Input["create_before_destroy_count"])
+	assertInt(t, "destroy_before_create_count", 1, result.Input["destroy_before_create_count"])
+	if got := result.Input["destroy_before_create_addresses"]; !reflect.DeepEqual(got, []string{"aws_db_instance.main"}) {
+		t.Errorf("destroy_before_create_addresses: got %v", got)
+	}
+	if got := result.Input["create_before_destroy_addresses"]; !reflect.DeepEqual(got, []string{"aws_launch_template.web"}) {
+		t.Errorf("create_before_destroy_addresses: got %v", got)
+	}
+
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+	assertStr(t, "db.replace_order", "destroy_before_create", byAddress["aws_db_instance.main"]["replace_order"])
+	assertStr(t, "lt.replace_order", "create_before_destroy", byAddress["aws_launch_template.web"]["replace_order"])
+	if _, ok := byAddress["aws_instance.web"]["replace_order"]; ok {
+		t.Error("replace_order is only set on replace entries")
+	}
+}
+
 // --- helpers ---
 
 func assertInt(t *testing.T, field string, want int, got any) {
@@ -0,0 +1,45 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// extractProtectedChanges lists the deletes and replaces that hit a
+// protected resource. Patterns containing a dot are matched against the
+// address (module.db.*, aws_kms_key.main), the others against the
+// resource type (aws_kms_key, aws_db_*). * matches any run of
+// characters, dots and brackets included.
+func extractProtectedChanges(changes []*tfjson.ResourceChange, patterns []string) []map[string]any {
+	out := []map[string]any{}
+	if len(patterns) == 0 {
+		return out
+	}
+	for _, rc := range changes {
+		action := primaryAction(rc.Change.Actions)
+		if action != "delete" && action != "replace" {
+			continue
+		}
+		for _, p := range patterns {
+			subject := rc.Type
+			if strings.Contains(p, ".") {
+				subject = rc.Address
+			}
+			if matchGlob(p, subject) {
+				out = append(out, map[string]any{
+					"address": rc.Address,
+					"type":    rc.Type,
+					"action":  action,
+					"pattern": p,
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i]["address"].(string) < out[j]["address"].(string)
+	})
+	return out
+}
@@ -0,0 +1,103 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_ProtectedChanges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		fixture   string
+		patterns  string
+		addresses []string
+	}{
+		{
+			name:      "type pattern",
+			fixture:   "mixed_changes.json",
+			patterns:  "hcloud_volume",
+			addresses: []string{"hcloud_volume.data"},
+		},
+		{
+			name:      "type glob hits delete and replace, not update",
+			fixture:   "mixed_changes.json",
+			patterns:  "hcloud_*",
+			addresses: []string{"hcloud_server.db", "hcloud_volume.data"},
+		},
+		{
+			name:      "address glob across module instances",
+			fixture:   "nested_modules.json",
+			patterns:  "module.database*",
+			addresses: []string{"module.database[0].hcloud_server.db[0]", "module.database[0].hcloud_volume.data"},
+		},
+		{
+			name:      "address glob without index does not match instances",
+			fixture:   "nested_modules.json",
+			patterns:  "module.database.*",
+			addresses: []string{},
+		},
+		{
+			name:      "creates are never protected changes",
+			fixture:   "nested_modules.json",
+			patterns:  "module.network.*,hcloud_network",
+			addresses: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			raw := loadFixture(t, tt.fixture)
+			result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+				"protected_resources": tt.patterns,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			addresses := []string{}
+			for _, c := range result.Input["protected_changes"].([]map[string]any) {
+				addresses = append(addresses, c["address"].(string))
+			}
+			if !reflect.DeepEqual(addresses, tt.addresses) {
+				t.Errorf("protected_changes: got %v, want %v", addresses, tt.addresses)
+			}
+			assertBool(t, "touches_protected", len(tt.addresses) > 0, result.Input["touches_protected"])
+		})
+	}
+}
+
+func TestPlanAdapter_ProtectedChanges_Entry(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "mixed_changes.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"protected_resources":  "hcloud_server.db",
+		"filter_actions":       "create",
+		"max_resource_changes": "0",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Not affected by filter_actions; the shortcut is computed before truncation.
+	assertInt(t, "protected_changes_total", 1, result.Input["protected_changes_total"])
+	assertBool(t, "protected_changes_truncated", true, result.Input["protected_changes_truncated"])
+	assertBool(t, "touches_protected", true, result.Input["touches_protected"])
+
+	result, err = (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"protected_resources": "hcloud_server.db",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []map[string]any{{
+		"address": "hcloud_server.db", "type": "hcloud_server", "action": "replace", "pattern": "hcloud_server.db",
+	}}
+	if got := result.Input["protected_changes"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("protected_changes: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package terraform
+
+import (
+	"encoding/json"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// defaultRedactPatterns are attribute names whose values are redacted
+// even when the provider does not mark them sensitive. Patterns given
+// in the redact_attributes config key are added to these.
+var defaultRedactPatterns = []string{
+	"password", "*_password",
+	"secret", "*_secret",
+	"private_key", "*_private_key",
+	"token", "*_token",
+}
+
+// redactor replaces sensitive values in plan changes with a marker
+// before anything is extracted from them:
+//
+//	{"redacted": true, "type": "string", "sha256": "<hex>"}
+//
+// The hash covers the salt and the JSON encoding of the value, so two
+// redacted values can be compared for equality without revealing them.
+type redactor struct {
+	patterns []string
+	salt     string
+	count    int
+}
+
+func newRedactor(patterns []string, salt string) *redactor {
+	all := append([]string{}, defaultRedactPatterns...)
+	for _, p := range patterns {
+		all = append(all, strings.ToLower(p))
+	}
+	return &redactor{patterns: all, salt: salt}
+}
+
+// redactPlan redacts every change in the plan that the adapter reads.
+func (r *redactor) redactPlan(plan *tfjson.Plan) {
+	for _, rc := range plan.ResourceChanges {
+		r.redactChange(rc.Change)
+	}
+	for _, rc := range plan.ResourceDrift {
+		r.redactChange(rc.Change)
+	}
+	for _, dc := range plan.DeferredChanges {
+		if dc.ResourceChange != nil {
+			r.redactChange(dc.ResourceChange.Change)
+		}
+	}
+	for name, c := range plan.OutputChanges {
+		if c == nil {
+			continue
+		}
+		// An output whose name matches is redacted as a whole.
+		if r.matches(name) {
+			c.Before, c.After = r.marker(c.Before), r.marker(c.After)
+			continue
+		}
+		r.redactChange(c)
+	}
+}
+
+func (r *redactor) redactChange(c *tfjson.Change) {
+	if c == nil {
+		return
+	}
+	c.Before = r.redact(c.Before, c.BeforeSensitive)
+	c.After = r.redact(c.After, c.AfterSensitive)
+}
+
+// redact walks v alongside its *_sensitive tree, which mirrors the value
+// with true at the sensitive leaves (or is true for the whole value).
+func (r *redactor) redact(v, sensitive any) any {
+	if b, ok := sensitive.(bool); ok && b {
+		return r.marker(v)
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		sens := asMap(sensitive)
+		for key, item := range x {
+			if r.matches(key) {
+				x[key] = r.marker(item)
+				continue
+			}
+			x[key] = r.redact(item, sens[key])
+		}
+	case []any:
+		sens, _ := sensitive.([]any)
+		for i, item := range x {
+			var s any
+			if i < len(sens) {
+				s = sens[i]
+			}
+			x[i] = r.redact(item, s)
+		}
+	}
+	return v
+}
+
+// marker replaces a value with its redaction marker. Null stays null:
+// there is nothing to hide and it keeps "removed" distinguishable.
+func (r *redactor) marker(v any) any {
+	if v == nil {
+		return nil
+	}
+	r.count++
+	data, _ := json.Marshal(v)
+	return map[string]any{
+		"redacted": true,
+		"type":     valueType(v),
+		"sha256":   sha256Hex(append([]byte(r.salt), data...)),
+	}
+}
+
+// isRedacted reports whether m is a redaction marker.
+func isRedacted(m map[string]any) bool {
+	redacted, _ := attrBool(m, "redacted")
+	return redacted && attrString(m, "sha256") != ""
+}
+
+func (r *redactor) matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range r.patterns {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+// matchGlob matches s against a pattern where * stands for any run of
+// characters, including none. No other character is special.
+func matchGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
@@ -0,0 +1,86 @@
+package terraform_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_Redaction_Sensitive(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "sensitive_values.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// password before/after, parameters[0].value before/after,
+	// labels.api_token before/after (default *_token pattern).
+	assertInt(t, "redacted_values", 6, result.Metadata["redacted_values"])
+
+	// Redacted values still compare: the changed password is reported,
+	// the unchanged parameter value is not.
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+	want := []string{"instance_class", "password"}
+	if got := byAddress["aws_db_instance.main"]["changed_attributes"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed_attributes: got %v, want %v", got, want)
+	}
+}
+
+func TestPlanAdapter_Redaction_WholeValueSensitive(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "sensitive_whole.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// before_sensitive/after_sensitive true: each side is one marker.
+	// Its keys (redacted, type, sha256) are not attributes; only
+	// after_unknown can name one.
+	byAddress := map[string]map[string]any{}
+	for _, c := range result.Input["resource_changes"].([]map[string]any) {
+		byAddress[c["address"].(string)] = c
+	}
+	for addr, want := range map[string][]string{
+		"aws_ssm_parameter.changed":   {"version"},
+		"aws_ssm_parameter.unchanged": {},
+	} {
+		if got := byAddress[addr]["changed_attributes"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s.changed_attributes: got %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestPlanAdapter_Redaction_NoLeakInFullOutput(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "sensitive_values.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"redact_attributes": "connection_*",
+		"redaction_salt":    "s3cr3t-salt",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertInt(t, "redacted_values", 8, result.Metadata["redacted_values"])
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, secret := range []string{"hunter2", "tok-123", "postgres://", "s3cr3t-salt"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("full output contains %q", secret)
+		}
+	}
+}
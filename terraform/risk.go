@@ -0,0 +1,201 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// riskTopN is the number of changes listed in risk_top_contributors.
+const riskTopN = 5
+
+// riskWeights drive the risk score. Each change in scope scores
+// actions[action] × the weight of its resource type (1 when no type
+// pattern matches); risk_score is the sum. Levels are the lowest score
+// of each level above "low".
+//
+// The risk_weights config key holds a JSON document of the same shape.
+// Its entries replace the defaults key by key, so a file can raise one
+// type without restating the rest; set a type to 1 to neutralise it.
+type riskWeights struct {
+	Actions       map[string]float64 `json:"actions"`
+	ResourceTypes map[string]float64 `json:"resource_types"`
+	Levels        map[string]float64 `json:"levels"`
+}
+
+// riskLevels are the level names from lowest to highest.
+var riskLevels = []string{"low", "medium", "high", "critical"}
+
+func defaultRiskWeights() riskWeights {
+	return riskWeights{
+		Actions: map[string]float64{
+			"create":  1,
+			"update":  2,
+			"forget":  2,
+			"replace": 8,
+			"delete":  10,
+		},
+		ResourceTypes: map[string]float64{
+			// Databases: data loss on delete or replace.
+			"aws_db_instance":                    5,
+			"aws_rds_cluster":                    5,
+			"aws_rds_cluster_instance":           3,
+			"aws_dynamodb_table":                 5,
+			"aws_docdb_cluster":                  5,
+			"aws_redshift_cluster":               5,
+			"aws_elasticache_*":                  3,
+			"google_sql_database_instance":       5,
+			"google_sql_database":                5,
+			"google_spanner_*":                   5,
+			"google_bigtable_instance":           5,
+			"azurerm_mssql_server":               5,
+			"azurerm_mssql_database":             5,
+			"azurerm_postgresql_flexible_server": 5,
+			"azurerm_mysql_flexible_server":      5,
+			"azurerm_cosmosdb_account":           5,
+			// Storage.
+			"aws_s3_bucket":           4,
+			"aws_ebs_volume":          4,
+			"aws_efs_file_system":     4,
+			"google_storage_bucket":   4,
+			"azurerm_storage_account": 4,
+			"hcloud_volume":           4,
+			// Keys: deleting one makes everything encrypted with it unreadable.
+			"aws_kms_key":           5,
+			"google_kms_crypto_key": 5,
+			"google_kms_key_ring":   5,
+			"azurerm_key_vault":     5,
+			"azurerm_key_vault_key": 5,
+			// DNS zones: delegation breaks and name servers change.
+			"aws_route53_zone":        5,
+			"google_dns_managed_zone": 5,
+			"azurerm_dns_zone":        5,
+			// Static addresses that clients may have pinned.
+			"aws_eip":            2,
+			"hcloud_primary_ip":  2,
+			"hcloud_floating_ip": 2,
+			// Access control.
+			"aws_iam_*":              3,
+			"google_project_iam_*":   3,
+			"google_service_account": 3,
+			"azurerm_role_*":         3,
+		},
+		Levels: map[string]float64{
+			"medium":   10,
+			"high":     30,
+			"critical": 60,
+		},
+	}
+}
+
+// parseRiskWeights merges a risk_weights document over the defaults.
+// Unlike unknown config keys, mistakes in the document are errors: a
+// misspelled key would otherwise quietly leave the default in place.
+func parseRiskWeights(doc string) (riskWeights, error) {
+	w := defaultRiskWeights()
+	if strings.TrimSpace(doc) == "" {
+		return w, nil
+	}
+	var override riskWeights
+	dec := json.NewDecoder(bytes.NewReader([]byte(doc)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&override); err != nil {
+		return w, err
+	}
+	for action, v := range override.Actions {
+		if _, ok := w.Actions[action]; !ok {
+			return w, fmt.Errorf("actions: unknown action %q", action)
+		}
+		w.Actions[action] = v
+	}
+	for typ, v := range override.ResourceTypes {
+		w.ResourceTypes[typ] = v
+	}
+	for level, v := range override.Levels {
+		if _, ok := w.Levels[level]; !ok {
+			return w, fmt.Errorf("levels: unknown level %q", level)
+		}
+		w.Levels[level] = v
+	}
+	for _, m := range []map[string]float64{w.Actions, w.ResourceTypes, w.Levels} {
+		for k, v := range m {
+			if v < 0 {
+				return w, fmt.Errorf("%s: negative weight %v", k, v)
+			}
+		}
+	}
+	for i := 2; i < len(riskLevels); i++ {
+		if w.Levels[riskLevels[i]] < w.Levels[riskLevels[i-1]] {
+			return w, fmt.Errorf("levels: %s is below %s", riskLevels[i], riskLevels[i-1])
+		}
+	}
+	return w, nil
+}
+
+// typeWeight returns the weight of a resource type: an exact entry, or
+// else the longest matching pattern (ties go to the first in byte
+// order), or 1.
+func (w riskWeights) typeWeight(typ string) float64 {
+	if v, ok := w.ResourceTypes[typ]; ok {
+		return v
+	}
+	best := ""
+	for p := range w.ResourceTypes {
+		if !strings.Contains(p, "*") || !matchGlob(p, typ) {
+			continue
+		}
+		if best == "" || len(p) > len(best) || (len(p) == len(best) && p < best) {
+			best = p
+		}
+	}
+	if best == "" {
+		return 1
+	}
+	return w.ResourceTypes[best]
+}
+
+func (w riskWeights) level(score float64) string {
+	level := riskLevels[0]
+	for _, l := range riskLevels[1:] {
+		if score >= w.Levels[l] {
+			level = l
+		}
+	}
+	return level
+}
+
+// scoreRisk scores the changes and lists the riskTopN largest
+// contributors ({address, type, action, score}), highest first.
+func scoreRisk(changes []*tfjson.ResourceChange, w riskWeights) (float64, string, []map[string]any) {
+	total := 0.0
+	contributors := []map[string]any{}
+	for _, rc := range changes {
+		action := primaryAction(rc.Change.Actions)
+		score := w.Actions[action] * w.typeWeight(rc.Type)
+		if score == 0 {
+			continue
+		}
+		total += score
+		contributors = append(contributors, map[string]any{
+			"address": rc.Address,
+			"type":    rc.Type,
+			"action":  action,
+			"score":   score,
+		})
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		si, sj := contributors[i]["score"].(float64), contributors[j]["score"].(float64)
+		if si != sj {
+			return si > sj
+		}
+		return contributors[i]["address"].(string) < contributors[j]["address"].(string)
+	})
+	if len(contributors) > riskTopN {
+		contributors = contributors[:riskTopN]
+	}
+	return total, w.level(total), contributors
+}
@@ -0,0 +1,103 @@
+package terraform_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_RiskScore(t *testing.T) {
+	t.Parallel()
+
+	// dependency_graph.json: s3 bucket delete (10×4), IAM role delete
+	// (10×3, via aws_iam_*), subnet replace (8×1), instance update (2×1).
+	raw := loadFixture(t, "dependency_graph.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := result.Input["risk_score"]; got != 80.0 {
+		t.Errorf("risk_score: expected 80, got %v", got)
+	}
+	assertStr(t, "risk_level", "critical", result.Input["risk_level"])
+
+	top := result.Input["risk_top_contributors"].([]map[string]any)
+	want := []string{"aws_s3_bucket.logs", "aws_iam_role.legacy", "aws_subnet.private", "module.app.aws_instance.web"}
+	if len(top) != len(want) {
+		t.Fatalf("risk_top_contributors: expected %d entries, got %d", len(want), len(top))
+	}
+	for i, addr := range want {
+		assertStr(t, "risk_top_contributors.address", addr, top[i]["address"])
+	}
+	if got := top[0]["score"]; got != 40.0 {
+		t.Errorf("risk_top_contributors[0].score: expected 40, got %v", got)
+	}
+}
+
+func TestPlanAdapter_RiskScore_Weights(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "dependency_graph.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		// The exact type wins over the aws_iam_* default; other defaults stay.
+		"risk_weights": `{
+			"actions": {"update": 0},
+			"resource_types": {"aws_iam_role": 1},
+			"levels": {"critical": 100}
+		}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.Input["risk_score"]; got != 58.0 {
+		t.Errorf("risk_score: expected 58, got %v", got)
+	}
+	assertStr(t, "risk_level", "high", result.Input["risk_level"])
+	if n := len(result.Input["risk_top_contributors"].([]map[string]any)); n != 3 {
+		t.Errorf("risk_top_contributors: zero-score changes must be left out, got %d entries", n)
+	}
+}
+
+func TestPlanAdapter_RiskScore_ScopeFilter(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "dependency_graph.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"filter_resource_types": "aws_subnet",
+		"filter_actions":        "create",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.Input["risk_score"]; got != 8.0 {
+		t.Errorf("risk_score: expected 8, got %v", got)
+	}
+	assertStr(t, "risk_level", "low", result.Input["risk_level"])
+}
+
+func TestPlanAdapter_RiskScore_InvalidWeights(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "simple_create.json")
+	for _, tc := range []struct {
+		name, weights, wantErr string
+	}{
+		{"malformed", `{"actions": `, "risk_weights"},
+		{"unknown key", `{"action": {"delete": 1}}`, "unknown field"},
+		{"unknown action", `{"actions": {"destroy": 1}}`, `unknown action "destroy"`},
+		{"negative", `{"resource_types": {"hcloud_server": -1}}`, "negative weight"},
+		{"unordered levels", `{"levels": {"high": 5}}`, "high is below medium"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+				"risk_weights": tc.weights,
+			})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// publicCannedACLs are the canned ACLs that grant access beyond the
+// bucket owner's account.
+var publicCannedACLs = map[string]bool{
+	"public-read":        true,
+	"public-read-write":  true,
+	"authenticated-read": true,
+}
+
+// s3Bucket collects everything the plan says about one bucket. Since AWS
+// provider v4 the settings live on separate resources that reference the
+// bucket; older configurations set them inline on aws_s3_bucket.
+type s3Bucket struct {
+	address string // aws_s3_bucket address; "" when not correlated
+	name    string
+	action  string
+
+	pab     map[string]any // aws_s3_bucket_public_access_block after state
+	pabAddr string
+	acl     string
+	public  bool
+	aclAddr string
+
+	sseAlgorithm string
+	sseKMSKey    bool
+	sseBucketKey bool
+	sseAddr      string
+}
+
+// extractS3Config correlates S3 bucket settings per bucket and returns
+// the s3_public_access_block and server_side_encryption entries plus the
+// addresses of buckets being created with no encryption configured.
+//
+// Sub-resources are matched to their bucket by the bucket name when it
+// is known at plan time, otherwise by the configuration reference in
+// their bucket argument (bucket = aws_s3_bucket.logs.id). Settings whose
+// bucket cannot be matched get an entry of their own.
+func extractS3Config(
+	changes []*tfjson.ResourceChange, idx *configIndex,
+) (pab, sse []map[string]any, unencrypted []string) {
+	var buckets []*s3Bucket
+	byAddress := map[string]*s3Bucket{}
+	byName := map[string]*s3Bucket{}
+	byStatic := map[string][]*tfjson.ResourceChange{}
+
+	for _, rc := range changes {
+		if rc.Type != "aws_s3_bucket" {
+			continue
+		}
+		after := asMap(rc.Change.After)
+		if after == nil {
+			continue
+		}
+		b := &s3Bucket{
+			address: rc.Address,
+			name:    attrString(after, "bucket"),
+			action:  primaryAction(rc.Change.Actions),
+		}
+		// Legacy inline arguments (AWS provider < v4).
+		if acl := attrString(after, "acl"); acl != "" {
+			b.acl, b.public, b.aclAddr = acl, publicCannedACLs[acl], rc.Address
+		}
+		for _, cfg := range attrBlocks(after, "server_side_encryption_configuration") {
+			b.readSSERules(attrBlocks(cfg, "rule"), rc.Address)
+		}
+		buckets = append(buckets, b)
+		byAddress[rc.Address] = b
+		if b.name != "" {
+			byName[b.name] = b
+		}
+		byStatic[staticAddress(rc)] = append(byStatic[staticAddress(rc)], rc)
+	}
+
+	resolve := func(rc *tfjson.ResourceChange, after map[string]any) *s3Bucket {
+		name := attrString(after, "bucket")
+		if b := byName[name]; b != nil {
+			return b
+		}
+		if cr := idx.lookup(rc); cr != nil {
+			if expr := cr.Expressions["bucket"]; expr != nil && expr.ExpressionData != nil {
+				path := stripIndexes(rc.ModuleAddress)
+				for _, ref := range expr.References {
+					if b := matchInstance(byStatic[resolveReference(path, ref)], rc, byAddress); b != nil {
+						return b
+					}
+				}
+			}
+		}
+		b := &s3Bucket{name: name, action: primaryAction(rc.Change.Actions)}
+		buckets = append(buckets, b)
+		if name != "" {
+			byName[name] = b
+		}
+		return b
+	}
+
+	for _, rc := range changes {
+		after := asMap(rc.Change.After)
+		if after == nil {
+			continue
+		}
+		switch rc.Type {
+		case "aws_s3_bucket_public_access_block":
+			b := resolve(rc, after)
+			b.pab, b.pabAddr = after, rc.Address
+		case "aws_s3_bucket_acl":
+			b := resolve(rc, after)
+			b.acl, b.aclAddr = attrString(after, "acl"), rc.Address
+			b.public = publicCannedACLs[b.acl]
+			for _, policy := range attrBlocks(after, "access_control_policy") {
+				for _, grant := range attrBlocks(policy, "grant") {
+					for _, grantee := range attrBlocks(grant, "grantee") {
+						uri := attrString(grantee, "uri")
+						if strings.HasSuffix(uri, "/AllUsers") || strings.HasSuffix(uri, "/AuthenticatedUsers") {
+							b.public = true
+						}
+					}
+				}
+			}
+		case "aws_s3_bucket_server_side_encryption_configuration":
+			b := resolve(rc, after)
+			b.readSSERules(attrBlocks(after, "rule"), rc.Address)
+		}
+	}
+
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].key() < buckets[j].key()
+	})
+
+	pab, sse, unencrypted = []map[string]any{}, []map[string]any{}, []string{}
+	for _, b := range buckets {
+		blockACLs, _ := attrBool(b.pab, "block_public_acls")
+		blockPolicy, _ := attrBool(b.pab, "block_public_policy")
+		ignoreACLs, _ := attrBool(b.pab, "ignore_public_acls")
+		restrict, _ := attrBool(b.pab, "restrict_public_buckets")
+		pab = append(pab, map[string]any{
+			"bucket_address":          b.address,
+			"bucket_name":             b.name,
+			"bucket_action":           b.action,
+			"address":                 b.pabAddr,
+			"configured":              b.pab != nil,
+			"block_public_acls":       blockACLs,
+			"block_public_policy":     blockPolicy,
+			"ignore_public_acls":      ignoreACLs,
+			"restrict_public_buckets": restrict,
+			"fully_blocked":           blockACLs && blockPolicy && ignoreACLs && restrict,
+			"acl":                     b.acl,
+			"acl_address":             b.aclAddr,
+			"public_acl":              b.public,
+		})
+		sse = append(sse, map[string]any{
+			"bucket_address":     b.address,
+			"bucket_name":        b.name,
+			"bucket_action":      b.action,
+			"address":            b.sseAddr,
+			"configured":         b.sseAlgorithm != "",
+			"sse_algorithm":      b.sseAlgorithm,
+			"kms_key_configured": b.sseKMSKey,
+			"bucket_key_enabled": b.sseBucketKey,
+		})
+		if b.address != "" && b.sseAlgorithm == "" &&
+			(b.action == "create" || b.action == "replace") {
+			unencrypted = append(unencrypted, b.address)
+		}
+	}
+	return pab, sse, unencrypted
+}
+
+func (b *s3Bucket) readSSERules(rules []map[string]any, addr string) {
+	for _, rule := range rules {
+		for _, def := range attrBlocks(rule, "apply_server_side_encryption_by_default") {
+			if alg := attrString(def, "sse_algorithm"); alg != "" {
+				b.sseAlgorithm = alg
+				b.sseKMSKey = attrString(def, "kms_master_key_id") != ""
+				b.sseAddr = addr
+			}
+		}
+		if enabled, ok := attrBool(rule, "bucket_key_enabled"); ok {
+			b.sseBucketKey = enabled
+		}
+	}
+}
+
+func (b *s3Bucket) key() string {
+	if b.address != "" {
+		return b.address
+	}
+	if b.pabAddr != "" {
+		return b.pabAddr
+	}
+	if b.aclAddr != "" {
+		return b.aclAddr
+	}
+	return b.sseAddr
+}
+
+// matchInstance picks the bucket a sub-resource refers to when the
+// referenced bucket block has several instances (count/for_each): the
+// instance with the same index wins, a single instance always matches.
+func matchInstance(
+	candidates []*tfjson.ResourceChange, rc *tfjson.ResourceChange, byAddress map[string]*s3Bucket,
+) *s3Bucket {
+	if len(candidates) == 1 {
+		return byAddress[candidates[0].Address]
+	}
+	for _, c := range candidates {
+		if c.Index != nil && c.Index == rc.Index {
+			return byAddress[c.Address]
+		}
+	}
+	return nil
+}
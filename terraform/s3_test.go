@@ -0,0 +1,107 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func entriesByKey(t *testing.T, v any) map[string]map[string]any {
+	t.Helper()
+	out := map[string]map[string]any{}
+	for _, e := range v.([]map[string]any) {
+		key := e["bucket_address"].(string)
+		if key == "" {
+			key = e["address"].(string)
+		}
+		out[key] = e
+	}
+	return out
+}
+
+func TestPlanAdapter_S3PublicAccessBlock(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_s3.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// aws_s3.json: 3 buckets + 1 public access block for a bucket
+	// outside this plan.
+	assertInt(t, "s3_public_access_block_total", 4, result.Input["s3_public_access_block_total"])
+	pab := entriesByKey(t, result.Input["s3_public_access_block"])
+
+	// logs: bucket name unknown at plan time, correlated via config reference.
+	logs := pab["aws_s3_bucket.logs"]
+	assertBool(t, "logs.configured", true, logs["configured"])
+	assertBool(t, "logs.fully_blocked", true, logs["fully_blocked"])
+	assertStr(t, "logs.address", "aws_s3_bucket_public_access_block.logs", logs["address"])
+
+	// assets: correlated by bucket name, public canned ACL, no block.
+	assets := pab["aws_s3_bucket.assets"]
+	assertBool(t, "assets.configured", false, assets["configured"])
+	assertBool(t, "assets.public_acl", true, assets["public_acl"])
+	assertStr(t, "assets.acl", "public-read", assets["acl"])
+	assertStr(t, "assets.acl_address", "aws_s3_bucket_acl.assets", assets["acl_address"])
+
+	// legacy: inline acl argument.
+	legacy := pab["aws_s3_bucket.legacy"]
+	assertStr(t, "legacy.acl", "private", legacy["acl"])
+	assertBool(t, "legacy.public_acl", false, legacy["public_acl"])
+
+	external := pab["aws_s3_bucket_public_access_block.external"]
+	assertStr(t, "external.bucket_name", "external-bucket", external["bucket_name"])
+	assertBool(t, "external.block_public_policy", false, external["block_public_policy"])
+	assertBool(t, "external.fully_blocked", false, external["fully_blocked"])
+}
+
+func TestPlanAdapter_S3ServerSideEncryption(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_s3.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sse := entriesByKey(t, result.Input["server_side_encryption"])
+
+	logs := sse["aws_s3_bucket.logs"]
+	assertBool(t, "logs.configured", true, logs["configured"])
+	assertStr(t, "logs.sse_algorithm", "aws:kms", logs["sse_algorithm"])
+	assertBool(t, "logs.kms_key_configured", true, logs["kms_key_configured"])
+	assertBool(t, "logs.bucket_key_enabled", true, logs["bucket_key_enabled"])
+
+	legacy := sse["aws_s3_bucket.legacy"]
+	assertStr(t, "legacy.sse_algorithm", "AES256", legacy["sse_algorithm"])
+	assertStr(t, "legacy.address", "aws_s3_bucket.legacy", legacy["address"])
+
+	assertBool(t, "assets.configured", false, sse["aws_s3_bucket.assets"]["configured"])
+
+	// Only newly created buckets without any encryption are listed.
+	unencrypted := result.Input["s3_buckets_without_encryption"].([]string)
+	if !reflect.DeepEqual(unencrypted, []string{"aws_s3_bucket.assets"}) {
+		t.Errorf("s3_buckets_without_encryption: got %v", unencrypted)
+	}
+	assertInt(t, "s3_buckets_without_encryption_total", 1, result.Input["s3_buckets_without_encryption_total"])
+}
+
+func TestPlanAdapter_S3_EmptyForNonAWS(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "simple_create.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, field := range []string{"s3_public_access_block", "server_side_encryption"} {
+		if n := len(result.Input[field].([]map[string]any)); n != 0 {
+			t.Errorf("%s: expected empty, got %d entries", field, n)
+		}
+	}
+	assertBool(t, "s3_public_access_block_truncated", false, result.Input["s3_public_access_block_truncated"])
+}
@@ -0,0 +1,151 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// sgRule is one normalized security group rule. The AWS provider models
+// the same rule three different ways (inline blocks on aws_security_group,
+// aws_security_group_rule, and the per-rule aws_vpc_security_group_*_rule
+// resources); policy sees a single shape.
+type sgRule struct {
+	address        string
+	resourceType   string
+	action         string
+	direction      string
+	protocol       string
+	fromPort       int
+	toPort         int
+	cidrBlocks     []string
+	ipv6CIDRBlocks []string
+	securityGroups []string
+	self           bool
+}
+
+func (r sgRule) fields() map[string]any {
+	return map[string]any{
+		"address":                    r.address,
+		"resource_type":              r.resourceType,
+		"action":                     r.action,
+		"direction":                  r.direction,
+		"protocol":                   r.protocol,
+		"from_port":                  r.fromPort,
+		"to_port":                    r.toPort,
+		"cidr_blocks":                r.cidrBlocks,
+		"ipv6_cidr_blocks":           r.ipv6CIDRBlocks,
+		"referenced_security_groups": r.securityGroups,
+		"self":                       r.self,
+	}
+}
+
+// extractSecurityGroupRules walks in-scope changes and returns one entry
+// per rule in the planned (after) state, sorted by address. Deletes have
+// no after state and are skipped: removing a rule never opens access.
+func extractSecurityGroupRules(changes []*tfjson.ResourceChange) []map[string]any {
+	var rules []sgRule
+	for _, rc := range changes {
+		after := asMap(rc.Change.After)
+		if after == nil {
+			continue
+		}
+		base := sgRule{
+			address:      rc.Address,
+			resourceType: rc.Type,
+			action:       primaryAction(rc.Change.Actions),
+		}
+
+		switch rc.Type {
+		case "aws_security_group":
+			for _, direction := range []string{"ingress", "egress"} {
+				for _, block := range attrBlocks(after, direction) {
+					r := base
+					r.direction = direction
+					r.protocol = normalizeProtocol(attrString(block, "protocol"))
+					r.fromPort, r.toPort = portRange(block, r.protocol)
+					r.cidrBlocks = attrStrings(block, "cidr_blocks")
+					r.ipv6CIDRBlocks = attrStrings(block, "ipv6_cidr_blocks")
+					r.securityGroups = attrStrings(block, "security_groups")
+					r.self, _ = attrBool(block, "self")
+					rules = append(rules, r)
+				}
+			}
+
+		case "aws_security_group_rule":
+			r := base
+			r.direction = attrString(after, "type")
+			r.protocol = normalizeProtocol(attrString(after, "protocol"))
+			r.fromPort, r.toPort = portRange(after, r.protocol)
+			r.cidrBlocks = attrStrings(after, "cidr_blocks")
+			r.ipv6CIDRBlocks = attrStrings(after, "ipv6_cidr_blocks")
+			r.securityGroups = nonEmpty(attrString(after, "source_security_group_id"))
+			r.self, _ = attrBool(after, "self")
+			rules = append(rules, r)
+
+		case "aws_vpc_security_group_ingress_rule", "aws_vpc_security_group_egress_rule":
+			r := base
+			r.direction = "ingress"
+			if rc.Type == "aws_vpc_security_group_egress_rule" {
+				r.direction = "egress"
+			}
+			r.protocol = normalizeProtocol(attrString(after, "ip_protocol"))
+			r.fromPort, r.toPort = portRange(after, r.protocol)
+			r.cidrBlocks = nonEmpty(attrString(after, "cidr_ipv4"))
+			r.ipv6CIDRBlocks = nonEmpty(attrString(after, "cidr_ipv6"))
+			r.securityGroups = nonEmpty(attrString(after, "referenced_security_group_id"))
+			rules = append(rules, r)
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].address < rules[j].address
+	})
+	out := make([]map[string]any, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, r.fields())
+	}
+	return out
+}
+
+// normalizeProtocol maps AWS protocol spellings to a canonical name.
+// "-1" and "all" both mean every protocol; well-known IP protocol
+// numbers are mapped to their names.
+func normalizeProtocol(p string) string {
+	switch p = strings.ToLower(p); p {
+	case "-1", "all":
+		return "all"
+	case "6":
+		return "tcp"
+	case "17":
+		return "udp"
+	case "1":
+		return "icmp"
+	case "58":
+		return "icmpv6"
+	}
+	return p
+}
+
+// portRange reads from_port/to_port. Rules for all protocols cover every
+// port regardless of what the configuration says (AWS requires 0 or -1
+// there, and the VPC rule resources leave them null).
+func portRange(m map[string]any, protocol string) (int, int) {
+	if protocol == "all" {
+		return 0, 65535
+	}
+	from, _ := attrInt(m, "from_port")
+	to, ok := attrInt(m, "to_port")
+	if !ok {
+		to = from
+	}
+	return from, to
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return []string{s}
+}
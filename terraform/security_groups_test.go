@@ -0,0 +1,120 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_SecurityGroupRules(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_security_groups.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// aws_security_groups.json: 2 inline rules + 1 legacy rule + 2 VPC rules.
+	// The deleted aws_security_group_rule.legacy has no after state.
+	rules := result.Input["security_group_rules"].([]map[string]any)
+	assertInt(t, "security_group_rules_total", 5, result.Input["security_group_rules_total"])
+	assertBool(t, "security_group_rules_truncated", false, result.Input["security_group_rules_truncated"])
+	if len(rules) != 5 {
+		t.Fatalf("expected 5 rules, got %d: %v", len(rules), rules)
+	}
+
+	for i := 1; i < len(rules); i++ {
+		if rules[i-1]["address"].(string) > rules[i]["address"].(string) {
+			t.Errorf("security_group_rules not sorted by address at %d", i)
+		}
+	}
+
+	byKey := map[string]map[string]any{}
+	for _, r := range rules {
+		if r["address"] == "aws_security_group_rule.legacy" {
+			t.Error("deleted rule must not appear in security_group_rules")
+		}
+		byKey[r["address"].(string)+"/"+r["direction"].(string)] = r
+	}
+
+	ssh := byKey["aws_security_group.web/ingress"]
+	assertStr(t, "ssh.protocol", "tcp", ssh["protocol"])
+	assertInt(t, "ssh.from_port", 22, ssh["from_port"])
+	assertInt(t, "ssh.to_port", 22, ssh["to_port"])
+	assertStr(t, "ssh.action", "create", ssh["action"])
+	if !reflect.DeepEqual(ssh["cidr_blocks"], []string{"0.0.0.0/0"}) {
+		t.Errorf("ssh.cidr_blocks: got %v", ssh["cidr_blocks"])
+	}
+
+	egress := byKey["aws_security_group.web/egress"]
+	assertStr(t, "egress.protocol", "all", egress["protocol"])
+	assertInt(t, "egress.from_port", 0, egress["from_port"])
+	assertInt(t, "egress.to_port", 65535, egress["to_port"])
+	if !reflect.DeepEqual(egress["ipv6_cidr_blocks"], []string{"::/0"}) {
+		t.Errorf("egress.ipv6_cidr_blocks: got %v", egress["ipv6_cidr_blocks"])
+	}
+
+	db := byKey["aws_security_group_rule.db_from_web/ingress"]
+	if !reflect.DeepEqual(db["referenced_security_groups"], []string{"sg-0123456789abcdef0"}) {
+		t.Errorf("db.referenced_security_groups: got %v", db["referenced_security_groups"])
+	}
+	if cidrs := db["cidr_blocks"].([]string); len(cidrs) != 0 {
+		t.Errorf("db.cidr_blocks: expected empty, got %v", cidrs)
+	}
+
+	https := byKey["aws_vpc_security_group_ingress_rule.https_v6/ingress"]
+	assertStr(t, "https.action", "update", https["action"])
+	assertStr(t, "https.resource_type", "aws_vpc_security_group_ingress_rule", https["resource_type"])
+	if !reflect.DeepEqual(https["ipv6_cidr_blocks"], []string{"::/0"}) {
+		t.Errorf("https.ipv6_cidr_blocks: got %v", https["ipv6_cidr_blocks"])
+	}
+
+	all := byKey["aws_vpc_security_group_egress_rule.all/egress"]
+	assertStr(t, "all.protocol", "all", all["protocol"])
+	assertInt(t, "all.to_port", 65535, all["to_port"])
+}
+
+func TestPlanAdapter_SecurityGroupRules_ScopeAndTruncation(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_security_groups.json")
+	a := &terraform.PlanAdapter{}
+
+	scoped, err := a.Convert(context.Background(), raw,
+		map[string]string{"filter_resource_types": "aws_security_group"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertInt(t, "security_group_rules_total", 2, scoped.Input["security_group_rules_total"])
+
+	capped, err := a.Convert(context.Background(), raw,
+		map[string]string{"max_resource_changes": "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(capped.Input["security_group_rules"].([]map[string]any)); n != 1 {
+		t.Errorf("expected 1 rule after truncation, got %d", n)
+	}
+	assertInt(t, "security_group_rules_total", 5, capped.Input["security_group_rules_total"])
+	assertBool(t, "security_group_rules_truncated", true, capped.Input["security_group_rules_truncated"])
+}
+
+func TestPlanAdapter_SecurityGroupRules_EmptyForNonAWS(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "simple_create.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rules, ok := result.Input["security_group_rules"].([]map[string]any)
+	if !ok || rules == nil {
+		t.Fatalf("security_group_rules must be a non-nil array, got %T", result.Input["security_group_rules"])
+	}
+	if len(rules) != 0 {
+		t.Errorf("expected no rules, got %v", rules)
+	}
+}
@@ -0,0 +1,106 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// tagAttributes lists, per provider prefix, the attributes holding a
+// resource's tags or labels, most complete first: tags_all and
+// effective_labels include provider-level defaults.
+var tagAttributes = []struct {
+	prefix string
+	attrs  []string
+}{
+	{"aws_", []string{"tags_all", "tags"}},
+	{"google_", []string{"effective_labels", "labels"}},
+	{"azurerm_", []string{"tags"}},
+	{"hcloud_", []string{"labels"}},
+}
+
+// extractTagCompliance checks created, updated and replaced resources
+// for the required tag keys. Keys match case-insensitively and an empty
+// value counts as missing. Resources without a tag attribute are not
+// taggable and are skipped; resources whose tags are unknown until
+// apply are counted but not judged.
+func extractTagCompliance(changes []*tfjson.ResourceChange, required []string, max int) map[string]any {
+	checked, unknown := 0, 0
+	nonCompliant := []map[string]any{}
+	for _, rc := range changes {
+		action := primaryAction(rc.Change.Actions)
+		if action != "create" && action != "update" && action != "replace" {
+			continue
+		}
+		tags, known, taggable := resourceTags(rc)
+		if !taggable {
+			continue
+		}
+		checked++
+		if !known {
+			unknown++
+			continue
+		}
+		present := map[string]bool{}
+		for k, v := range tags {
+			if s, _ := v.(string); s != "" {
+				present[strings.ToLower(k)] = true
+			}
+		}
+		missing := []string{}
+		for _, key := range required {
+			if !present[strings.ToLower(key)] {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			nonCompliant = append(nonCompliant, map[string]any{
+				"address":      rc.Address,
+				"type":         rc.Type,
+				"action":       action,
+				"missing_keys": missing,
+			})
+		}
+	}
+	sort.Slice(nonCompliant, func(i, j int) bool {
+		return nonCompliant[i]["address"].(string) < nonCompliant[j]["address"].(string)
+	})
+	violations := len(nonCompliant)
+	nonCompliant, _, truncated := truncate(nonCompliant, max)
+
+	return map[string]any{
+		"required_keys":           required,
+		"checked_count":           checked,
+		"compliant_count":         checked - unknown - violations,
+		"non_compliant_count":     violations,
+		"unknown_count":           unknown,
+		"non_compliant":           nonCompliant,
+		"non_compliant_truncated": truncated,
+	}
+}
+
+// resourceTags returns the tag map of a resource change's planned state.
+// known is false when the tags are unknown until apply; taggable is
+// false when the resource type has no tag attribute at all.
+func resourceTags(rc *tfjson.ResourceChange) (tags map[string]any, known, taggable bool) {
+	after := asMap(rc.Change.After)
+	afterUnknown := asMap(rc.Change.AfterUnknown)
+	for _, ta := range tagAttributes {
+		if !strings.HasPrefix(rc.Type, ta.prefix) {
+			continue
+		}
+		for _, attr := range ta.attrs {
+			// Falling back to tags when tags_all is unknown would miss
+			// keys that provider default_tags add.
+			if u, _ := attrBool(afterUnknown, attr); u {
+				return nil, false, true
+			}
+			if v, ok := after[attr]; ok {
+				// A null tags argument is an empty map.
+				return asMap(v), true, true
+			}
+		}
+	}
+	return nil, false, false
+}
@@ -0,0 +1,68 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func TestPlanAdapter_TagCompliance(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "tags.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"required_tags": "owner, cost-center, env",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tc := result.Input["tag_compliance"].(map[string]any)
+	if got := tc["required_keys"]; !reflect.DeepEqual(got, []string{"cost-center", "env", "owner"}) {
+		t.Errorf("required_keys: got %v", got)
+	}
+	// Checked: 6 taggable creates/updates/replaces; the policy attachment has
+	// no tags and the delete is not checked.
+	assertInt(t, "checked_count", 6, tc["checked_count"])
+	assertInt(t, "compliant_count", 3, tc["compliant_count"])
+	assertInt(t, "non_compliant_count", 2, tc["non_compliant_count"])
+	// aws_vpc.main: tags_all unknown until apply; default_tags may still add keys.
+	assertInt(t, "unknown_count", 1, tc["unknown_count"])
+	assertBool(t, "has_tag_violations", true, result.Input["has_tag_violations"])
+
+	nonCompliant := tc["non_compliant"].([]map[string]any)
+	want := []map[string]any{
+		{
+			"address": "aws_s3_bucket.partial", "type": "aws_s3_bucket", "action": "update",
+			// An empty value counts as missing.
+			"missing_keys": []string{"cost-center", "env"},
+		},
+		{
+			"address": "hcloud_server.web", "type": "hcloud_server", "action": "create",
+			"missing_keys": []string{"cost-center", "env", "owner"},
+		},
+	}
+	if !reflect.DeepEqual(nonCompliant, want) {
+		t.Errorf("non_compliant:\n got %v\nwant %v", nonCompliant, want)
+	}
+	assertBool(t, "non_compliant_truncated", false, tc["non_compliant_truncated"])
+}
+
+func TestPlanAdapter_TagCompliance_NoRequiredKeys(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "tags.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tc := result.Input["tag_compliance"].(map[string]any)
+	assertInt(t, "non_compliant_count", 0, tc["non_compliant_count"])
+	if got := tc["required_keys"]; !reflect.DeepEqual(got, []string{}) {
+		t.Errorf("required_keys: expected empty, got %v", got)
+	}
+	assertBool(t, "has_tag_violations", false, result.Input["has_tag_violations"])
+}
@@ -0,0 +1,183 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// extractTrustPolicyStatements parses assume_role_policy on in-scope
+// aws_iam_role changes. Each statement is flagged for the patterns trust
+// policy rules care about:
+//
+//   - wildcard_principal: any principal identifier is "*"
+//   - cross_account: an AWS principal is "*" or belongs to an account
+//     that is not the role's own account and not listed in
+//     trusted_account_ids. The own account comes from the role's ARN,
+//     which a new role does not have yet, and from the assume_role and
+//     allowed_account_ids of its provider configuration.
+//   - cross_account_unknown: the own account is not known, so a
+//     principal account outside trusted_account_ids cannot be judged.
+//     Such a statement is not flagged cross_account.
+//   - unrestricted_web_identity: a federated principal may call
+//     AssumeRoleWithWebIdentity without a condition pinning the token
+//     subject (":sub" key) to something narrower than "*".
+//
+// Flags are only set on Allow statements; Deny statements can only
+// narrow who may assume the role.
+func extractTrustPolicyStatements(
+	changes []*tfjson.ResourceChange, trustedAccounts map[string]bool,
+	cfg *tfjson.Config, idx *configIndex,
+) ([]map[string]any, []string) {
+	out := []map[string]any{}
+	var warnings []string
+
+	for _, rc := range changes {
+		if rc.Type != "aws_iam_role" {
+			continue
+		}
+		after := asMap(rc.Change.After)
+		doc, known := after["assume_role_policy"].(string)
+		if !known || doc == "" {
+			continue
+		}
+		statements, err := parsePolicyDocument(doc)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: cannot parse assume_role_policy: %v", rc.Address, err))
+			continue
+		}
+
+		home := map[string]bool{}
+		for _, state := range []map[string]any{asMap(rc.Change.Before), after} {
+			if acct := arnAccount(attrString(state, "arn")); acct != "" {
+				home[acct] = true
+			}
+		}
+		for _, acct := range providerAccounts(providerConfigFor(rc, cfg, idx)) {
+			home[acct] = true
+		}
+
+		action := primaryAction(rc.Change.Actions)
+		for _, st := range statements {
+			accounts := principalAccounts(st.Principals["AWS"])
+			allow := st.Effect == "Allow"
+
+			crossAccount, unknown := false, false
+			for _, acct := range accounts {
+				switch {
+				case acct == "*":
+					crossAccount = true
+				case home[acct] || trustedAccounts[acct]:
+				case len(home) == 0:
+					unknown = true
+				default:
+					crossAccount = true
+				}
+			}
+
+			entry := map[string]any{
+				"address":                   rc.Address,
+				"action":                    action,
+				"sid":                       st.Sid,
+				"effect":                    st.Effect,
+				"actions":                   st.Actions,
+				"principals":                st.Principals,
+				"conditions":                conditionFields(st.Conditions),
+				"accounts":                  accounts,
+				"cross_account":             allow && crossAccount,
+				"cross_account_unknown":     allow && unknown,
+				"wildcard_principal":        allow && hasWildcardPrincipal(st.Principals),
+				"unrestricted_web_identity": allow && unrestrictedWebIdentity(st),
+			}
+			out = append(out, entry)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i]["address"].(string) < out[j]["address"].(string)
+	})
+	return out, warnings
+}
+
+// principalAccounts extracts account IDs from AWS principals, which may
+// be bare 12-digit IDs, IAM/STS ARNs, or "*".
+func principalAccounts(ids []string) []string {
+	seen := map[string]bool{}
+	for _, id := range ids {
+		switch {
+		case id == "*":
+			seen["*"] = true
+		case strings.HasPrefix(id, "arn:"):
+			if acct := arnAccount(id); acct != "" {
+				seen[acct] = true
+			}
+		case isAccountID(id):
+			seen[id] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+func hasWildcardPrincipal(principals map[string][]string) bool {
+	for _, ids := range principals {
+		for _, id := range ids {
+			if id == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func unrestrictedWebIdentity(st policyStatement) bool {
+	if len(st.Principals["Federated"]) == 0 {
+		return false
+	}
+	webIdentity := false
+	for _, a := range st.Actions {
+		if a == "*" || a == "sts:*" || strings.EqualFold(a, "sts:AssumeRoleWithWebIdentity") {
+			webIdentity = true
+		}
+	}
+	if !webIdentity {
+		return false
+	}
+	for _, c := range st.Conditions {
+		if !strings.HasSuffix(c.Key, ":sub") {
+			continue
+		}
+		for _, v := range c.Values {
+			if v != "*" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// arnAccount returns the account field of an ARN
+// (arn:partition:service:region:account:resource), or "".
+func arnAccount(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	if !isAccountID(parts[4]) {
+		return ""
+	}
+	return parts[4]
+}
+
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package terraform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vitas/evidra-adapters/terraform"
+)
+
+func trustBySid(t *testing.T, input map[string]any) map[string]map[string]any {
+	t.Helper()
+	out := map[string]map[string]any{}
+	for _, st := range input["trust_policy_statements"].([]map[string]any) {
+		out[st["address"].(string)+"/"+st["sid"].(string)] = st
+	}
+	return out
+}
+
+func TestPlanAdapter_TrustPolicyStatements(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_iam_trust.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertInt(t, "trust_policy_statements_total", 6, result.Input["trust_policy_statements_total"])
+	assertBool(t, "trust_policy_statements_truncated", false, result.Input["trust_policy_statements_truncated"])
+	assertBool(t, "has_cross_account_trust", true, result.Input["has_cross_account_trust"])
+	assertBool(t, "has_wildcard_trust", true, result.Input["has_wildcard_trust"])
+
+	st := trustBySid(t, result.Input)
+
+	oidc := st["aws_iam_role.ci_oidc/"]
+	assertBool(t, "ci_oidc.unrestricted_web_identity", true, oidc["unrestricted_web_identity"])
+	assertBool(t, "ci_oidc.cross_account", false, oidc["cross_account"])
+	if !reflect.DeepEqual(oidc["actions"], []string{"sts:AssumeRoleWithWebIdentity"}) {
+		t.Errorf("ci_oidc.actions: got %v", oidc["actions"])
+	}
+
+	scoped := st["aws_iam_role.github_scoped/"]
+	assertBool(t, "github_scoped.unrestricted_web_identity", false, scoped["unrestricted_web_identity"])
+
+	// partner's own account comes from its ARN.
+	partner := st["aws_iam_role.partner/Partner"]
+	assertBool(t, "partner.cross_account", true, partner["cross_account"])
+	if !reflect.DeepEqual(partner["accounts"], []string{"444455556666"}) {
+		t.Errorf("partner.accounts: got %v", partner["accounts"])
+	}
+	self := st["aws_iam_role.partner/Self"]
+	assertBool(t, "self.cross_account", false, self["cross_account"])
+
+	public := st["aws_iam_role.public/"]
+	assertBool(t, "public.wildcard_principal", true, public["wildcard_principal"])
+	assertBool(t, "public.cross_account", true, public["cross_account"])
+
+	ec2 := st["aws_iam_role.ec2/"]
+	for _, flag := range []string{"cross_account", "wildcard_principal", "unrestricted_web_identity"} {
+		assertBool(t, "ec2."+flag, false, ec2[flag])
+	}
+	principals := ec2["principals"].(map[string][]string)
+	if !reflect.DeepEqual(principals["Service"], []string{"ec2.amazonaws.com"}) {
+		t.Errorf("ec2.principals: got %v", principals)
+	}
+}
+
+func TestPlanAdapter_TrustPolicyStatements_TrustedAccounts(t *testing.T) {
+	t.Parallel()
+
+	raw := loadFixture(t, "aws_iam_trust.json")
+	config := map[string]string{
+		"trusted_account_ids":   "444455556666",
+		"filter_resource_types": "aws_iam_role",
+	}
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	st := trustBySid(t, result.Input)
+	assertBool(t, "partner.cross_account", false, st["aws_iam_role.partner/Partner"]["cross_account"])
+	// "*" is never a trusted account.
+	assertBool(t, "public.cross_account", true, st["aws_iam_role.public/"]["cross_account"])
+}
+
+func TestPlanAdapter_TrustPolicyStatements_NewRoles(t *testing.T) {
+	t.Parallel()
+
+	// New roles have no ARN yet: their own account comes from the
+	// provider configuration (allowed_account_ids, assume_role.role_arn).
+	raw := loadFixture(t, "aws_iam_trust_new_roles.json")
+	result, err := (&terraform.PlanAdapter{}).Convert(context.Background(), raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	st := trustBySid(t, result.Input)
+	for addr, want := range map[string][2]bool{
+		// {cross_account, cross_account_unknown}
+		"aws_iam_role.self/":    {false, false},
+		"aws_iam_role.partner/": {true, false},
+		"aws_iam_role.deploy/":  {false, false},
+		// The sandbox provider names no account: not judged.
+		"aws_iam_role.sandbox/": {false, true},
+	} {
+		assertBool(t, addr+"cross_account", want[0], st[addr]["cross_account"])
+		assertBool(t, addr+"cross_account_unknown", want[1], st[addr]["cross_account_unknown"])
+	}
+	assertBool(t, "has_cross_account_trust", true, result.Input["has_cross_account_trust"])
+
+	// allowed_account_ids and role_arn also feed accounts.
+	want := []string{"111122223333", "777788889999"}
+	if got := result.Input["accounts"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("accounts: got %v, want %v", got, want)
+	}
+
+	// With the partner account trusted, nothing is known to be cross-account.
+	result, err = (&terraform.PlanAdapter{}).Convert(context.Background(), raw, map[string]string{
+		"trusted_account_ids": "444455556666",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertBool(t, "has_cross_account_trust (trusted partner)", false, result.Input["has_cross_account_trust"])
+}
@@ -0,0 +1,75 @@
+package terraform
+
+import (
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// identityAttributes identify the remote object. When one of them is
+// unknown on an update, the provider may be about to point the resource
+// at a different object even though the plan does not say "replace".
+var identityAttributes = []string{"id", "arn", "name"}
+
+// unknownPaths returns the sorted paths of the leaves of an after_unknown
+// tree. A block or collection that is unknown as a whole is one path.
+func unknownPaths(tree any) []string {
+	paths := []string{}
+	var walk func(path []any, v any)
+	walk = func(path []any, v any) {
+		switch x := v.(type) {
+		case bool:
+			if x && len(path) > 0 {
+				paths = append(paths, formatPath(path))
+			}
+		case map[string]any:
+			for k, item := range x {
+				walk(append(path[:len(path):len(path)], k), item)
+			}
+		case []any:
+			for i, item := range x {
+				walk(append(path[:len(path):len(path)], float64(i)), item)
+			}
+		}
+	}
+	walk(nil, tree)
+	sort.Strings(paths)
+	return paths
+}
+
+// knownLeaves counts the non-null scalar values in change.after. Unknown
+// values are absent there, and redacted values count as one leaf.
+func knownLeaves(v any) int {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case map[string]any:
+		if isRedacted(x) {
+			return 1
+		}
+		n := 0
+		for _, item := range x {
+			n += knownLeaves(item)
+		}
+		return n
+	case []any:
+		n := 0
+		for _, item := range x {
+			n += knownLeaves(item)
+		}
+		return n
+	}
+	return 1
+}
+
+// identityUnknown reports whether an identity-bearing top-level
+// attribute is unknown until apply.
+func identityUnknown(c *tfjson.Change) bool {
+	unknown := asMap(c.AfterUnknown)
+	for _, attr := range identityAttributes {
+		if b, _ := attrBool(unknown, attr); b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Helpers for reading attribute values out of tfjson change objects.
+// Plan JSON decodes into untyped trees: objects are map[string]any,
+// lists and sets are []any, numbers are float64. Values that are
+// unknown until apply are simply absent from change.after.
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func attrString(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func attrBool(m map[string]any, key string) (bool, bool) {
+	b, ok := m[key].(bool)
+	return b, ok
+}
+
+func attrInt(m map[string]any, key string) (int, bool) {
+	switch v := m[key].(type) {
+	case float64:
+		return int(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err == nil
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// attrStrings returns the non-empty string elements of a list attribute.
+// The result is never nil so it serialises as [] rather than null.
+func attrStrings(m map[string]any, key string) []string {
+	list, _ := m[key].([]any)
+	out := []string{}
+	for _, item := range list {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// attrBlocks returns the object elements of a nested block attribute.
+func attrBlocks(m map[string]any, key string) []map[string]any {
+	list, _ := m[key].([]any)
+	var out []map[string]any
+	for _, item := range list {
+		if b, ok := item.(map[string]any); ok {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// containsTrue reports whether v is true or a collection containing
+// true at any depth. after_unknown and *_sensitive use this shape: a
+// tree mirroring the value with true at the unknown/sensitive leaves.
+func containsTrue(v any) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case map[string]any:
+		for _, item := range x {
+			if containsTrue(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range x {
+			if containsTrue(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatPath renders an attribute path as used in replace_paths
+// (["root_block_device", 0, "volume_size"]) in Terraform's own syntax:
+// root_block_device[0].volume_size. Map keys that are not plain
+// identifiers are quoted: tags["kubernetes.io/role"].
+func formatPath(path []any) string {
+	var b strings.Builder
+	for i, step := range path {
+		switch s := step.(type) {
+		case string:
+			if i == 0 {
+				b.WriteString(s)
+			} else if isIdentifier(s) {
+				b.WriteString("." + s)
+			} else {
+				b.WriteString("[" + strconv.Quote(s) + "]")
+			}
+		case float64:
+			b.WriteString(fmt.Sprintf("[%d]", int(s)))
+		default:
+			b.WriteString(fmt.Sprintf("[%v]", s))
+		}
+	}
+	return b.String()
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		letter := r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if !letter && (i == 0 || r != '-' && (r < '0' || r > '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncate caps s at max entries and reports the original length and
+// whether anything was dropped. A negative max disables the cap.
+func truncate[T any](s []T, max int) ([]T, int, bool) {
+	total := len(s)
+	if max >= 0 && total > max {
+		return s[:max], total, true
+	}
+	return s, total, false
+}